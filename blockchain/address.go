@@ -0,0 +1,90 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// persistAddressIdentifications indexes the IDs registered by the transaction
+// to every address spent by its inputs. txs are the transactions of the
+// block, an input may spend an output of an earlier transaction of the same
+// block which is not committed yet.
+func (c *IDChainStore) persistAddressIdentifications(batch *indexBatch,
+	txs map[common.Uint256]*types.Transaction, txn *types.Transaction, IDs []string) error {
+	programHashes, err := c.inputProgramHashes(txs, txn)
+	if err != nil {
+		return err
+	}
+
+	txHash := txn.Hash()
+	for _, programHash := range programHashes {
+		for _, ID := range IDs {
+			key := []byte{byte(IX_AddressIdentification)}
+			key = append(key, programHash.Bytes()...)
+			key = append(key, ID...)
+			batch.Put(key, txHash.Bytes())
+		}
+	}
+	return nil
+}
+
+// inputProgramHashes returns the distinct program hashes of the outputs spent
+// by the transaction.
+func (c *IDChainStore) inputProgramHashes(txs map[common.Uint256]*types.Transaction,
+	txn *types.Transaction) ([]common.Uint168, error) {
+	var programHashes []common.Uint168
+	seen := make(map[common.Uint168]struct{})
+	for _, input := range txn.Inputs {
+		prev, ok := txs[input.Previous.TxID]
+		if !ok {
+			var err error
+			prev, _, err = c.GetTransaction(input.Previous.TxID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if int(input.Previous.Index) >= len(prev.Outputs) {
+			return nil, errors.New("[IDChainStore], input refers to an unknown output")
+		}
+
+		programHash := prev.Outputs[input.Previous.Index].ProgramHash
+		if _, ok := seen[programHash]; ok {
+			continue
+		}
+		seen[programHash] = struct{}{}
+		programHashes = append(programHashes, programHash)
+	}
+
+	return programHashes, nil
+}
+
+// ForEachAddressIdentification calls fn with the IDs registered by the
+// address of the program hash ordered after the ID after, and the hash of
+// the transaction that last registered them from the address, until fn
+// returns false. An empty after starts from the first ID.
+func (c *IDChainStore) ForEachAddressIdentification(programHash common.Uint168, after string,
+	fn func(ID string, txHash common.Uint256) bool) error {
+	prefix := []byte{byte(IX_AddressIdentification)}
+	prefix = append(prefix, programHash.Bytes()...)
+	iter := c.NewIterator(prefix)
+	defer iter.Release()
+
+	for ok := iter.Seek(append(prefix, after...)); ok; ok = iter.Next() {
+		ID := string(iter.Key()[len(prefix):])
+		if ID <= after {
+			continue
+		}
+
+		txHash, err := common.Uint256FromBytes(iter.Value())
+		if err != nil {
+			return err
+		}
+		if !fn(ID, *txHash) {
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestAddressIdentifications(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	address := common.Uint168{0x67, 1}
+	stranger := common.Uint168{0x67, 2}
+	funding := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &types.PayloadTransferAsset{},
+		Outputs: []*types.Output{
+			{Value: 1, ProgramHash: address},
+			{Value: 1, ProgramHash: address},
+			{Value: 1, ProgramHash: stranger},
+		},
+	}
+	saveTestBlock(t, store, funding)
+
+	// An input spending an output of an earlier transaction of the same
+	// block is indexed too.
+	change := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &types.PayloadTransferAsset{},
+		Inputs:  []*types.Input{{Previous: types.OutPoint{TxID: funding.Hash(), Index: 2}}},
+		Outputs: []*types.Output{{Value: 1, ProgramHash: stranger}},
+	}
+	first := registerTx(ID, "kyc/person/identityCard")
+	first.Inputs = []*types.Input{
+		{Previous: types.OutPoint{TxID: funding.Hash(), Index: 0}},
+		{Previous: types.OutPoint{TxID: funding.Hash(), Index: 1}},
+	}
+	first.Outputs = []*types.Output{{Value: 1, ProgramHash: address}}
+	second := registerTx(other, "kyc/person/phone")
+	second.Inputs = []*types.Input{{Previous: types.OutPoint{TxID: change.Hash(), Index: 0}}}
+	saveTestBlock(t, store, change, first, second)
+
+	list := func(programHash common.Uint168, after string) map[string]common.Uint256 {
+		IDs := make(map[string]common.Uint256)
+		err := store.ForEachAddressIdentification(programHash, after, func(ID string, txHash common.Uint256) bool {
+			IDs[ID] = txHash
+			return true
+		})
+		if err != nil {
+			t.Fatal("address identifications error:", err)
+		}
+		return IDs
+	}
+
+	IDs := list(address, "")
+	if len(IDs) != 1 || !IDs[ID].IsEqual(first.Hash()) {
+		t.Errorf("IDs of the address %v", IDs)
+	}
+	IDs = list(stranger, "")
+	if len(IDs) != 1 || !IDs[other].IsEqual(second.Hash()) {
+		t.Errorf("IDs of another address %v", IDs)
+	}
+	if IDs = list(stranger, other); len(IDs) != 0 {
+		t.Errorf("IDs of another address after its last ID %v", IDs)
+	}
+
+	// Registering again from the address points it to the last transaction,
+	// until the block is rolled back.
+	again := registerTx(ID, "kyc/person/phone")
+	again.Inputs = []*types.Input{{Previous: types.OutPoint{TxID: first.Hash(), Index: 0}}}
+	block := saveTestBlock(t, store, again)
+	if IDs = list(address, ""); len(IDs) != 1 || !IDs[ID].IsEqual(again.Hash()) {
+		t.Errorf("IDs of the address registered again %v", IDs)
+	}
+	if err := store.RollbackBlock(block.Hash()); err != nil {
+		t.Fatal("rollback error:", err)
+	}
+	if IDs = list(address, ""); len(IDs) != 1 || !IDs[ID].IsEqual(first.Hash()) {
+		t.Errorf("IDs of the address after the rollback %v", IDs)
+	}
+}
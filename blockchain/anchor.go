@@ -0,0 +1,30 @@
+package blockchain
+
+import (
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// persistAnchorTx indexes the hash to the anchoring transaction, a hash
+// anchored again later keeps pointing to the transaction that first
+// anchored it.
+func (c *IDChainStore) persistAnchorTx(batch *indexBatch, hash common.Uint256, txHash common.Uint256) {
+	key := []byte{byte(IX_Anchor)}
+	key = append(key, hash.Bytes()...)
+
+	if _, err := batch.Get(key); err == nil {
+		return
+	}
+	batch.Put(key, txHash.Bytes())
+}
+
+// GetAnchorTx returns the hash of the transaction that first anchored the
+// hash.
+func (c *IDChainStore) GetAnchorTx(hash common.Uint256) (*common.Uint256, error) {
+	key := []byte{byte(IX_Anchor)}
+	data, err := c.Get(append(key, hash.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Uint256FromBytes(data)
+}
@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// CheckIdentificationBlockContext is the name of the identification block
+// context check registered on the side chain block validator.
+const CheckIdentificationBlockContext = "checkidentificationblockcontext"
+
+// CheckCoinbaseReward is the name of the coinbase reward check registered on
+// the side chain block validator.
+const CheckCoinbaseReward = "checkcoinbasereward"
+
+// CheckBlockContext checks the identification rules spanning the transactions
+// of a block. The block validator runs it when the block is accepted, before
+// the chain is connected to it or reorganized.
+func (c *IDChainStore) CheckBlockContext(args ...interface{}) error {
+	block, err := blockArg(args)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkCheckpoint(block); err != nil {
+		return err
+	}
+	return checkSequenceConflicts(block)
+}
+
+// NewCoinbaseRewardCheck returns the check of the share of the coinbase
+// rewards paid to the foundation. The share is the one of the reward schedule
+// at the height of the block, which is not the next height for a block of a
+// fork or an orphan block.
+func NewCoinbaseRewardCheck(foundation common.Uint168, rewards params.RewardSchedule) func(args ...interface{}) error {
+	return func(args ...interface{}) error {
+		block, err := blockArg(args)
+		if err != nil {
+			return err
+		}
+		if len(block.Transactions) == 0 {
+			return nil
+		}
+		return checkCoinbaseReward(block.Transactions[0], foundation,
+			rewards.FoundationShare(block.Header.Height))
+	}
+}
+
+func checkCoinbaseReward(coinbase *types.Transaction, foundation common.Uint168, share float64) error {
+	var totalReward, foundationReward common.Fixed64
+	for _, output := range coinbase.Outputs {
+		totalReward += output.Value
+		if output.ProgramHash.IsEqual(foundation) {
+			foundationReward += output.Value
+		}
+	}
+	if foundationReward < common.Fixed64(float64(totalReward)*share) {
+		return errors.New("[checkCoinbaseReward] Reward to foundation in coinbase < " +
+			strconv.FormatFloat(share*100, 'f', -1, 64) + "%")
+	}
+	return nil
+}
+
+// blockArg returns the block among the arguments of a block check.
+func blockArg(args []interface{}) (*types.Block, error) {
+	for _, arg := range args {
+		if b, ok := arg.(*types.Block); ok {
+			return b, nil
+		}
+	}
+	return nil, errors.New("[IDChainStore], block context check without a block")
+}
@@ -0,0 +1,39 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestCoinbaseRewardCheck(t *testing.T) {
+	foundation := common.Uint168{0x12, 1}
+	check := NewCoinbaseRewardCheck(foundation, params.RewardSchedule{
+		{Height: 0, FoundationShare: 0.3},
+		{Height: 10, FoundationShare: 0.5},
+	})
+	block := func(height uint32) *types.Block {
+		return &types.Block{
+			Header: types.Header{Height: height},
+			Transactions: []*types.Transaction{{Outputs: []*types.Output{
+				{ProgramHash: foundation, Value: 30},
+				{ProgramHash: common.Uint168{0x21, 2}, Value: 70},
+			}}},
+		}
+	}
+
+	// The share is the one at the height of the block, whatever the best
+	// block is.
+	if err := check(block(9)); err != nil {
+		t.Error("coinbase reward before the schedule change error:", err)
+	}
+	if err := check(block(10)); err == nil {
+		t.Error("coinbase reward below the share of the block should be rejected!")
+	}
+	if err := check(); err == nil {
+		t.Error("coinbase reward check without a block should fail!")
+	}
+}
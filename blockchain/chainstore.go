@@ -2,7 +2,10 @@ package blockchain
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
 	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
 
 	"github.com/elastos/Elastos.ELA.SideChain/blockchain"
@@ -13,19 +16,27 @@ import (
 
 type IDChainStore struct {
 	*blockchain.ChainStore
+
+	activations params.Activations
+	checkpoints params.Checkpoints
+	listeners   indexListeners
 }
 
-func NewChainStore(genesisBlock *types.Block, dataPath string) (*IDChainStore, error) {
+func NewChainStore(genesisBlock *types.Block, activations params.Activations,
+	checkpoints params.Checkpoints, dataPath string) (*IDChainStore, error) {
 	chainStore, err := blockchain.NewChainStore(dataPath, genesisBlock)
 	if err != nil {
 		return nil, err
 	}
 
 	store := &IDChainStore{
-		ChainStore: chainStore,
+		ChainStore:  chainStore,
+		activations: activations,
+		checkpoints: checkpoints,
 	}
 
 	store.RegisterFunctions(true, blockchain.StoreFuncNames.PersistTransactions, store.persistTransactions)
+	store.RegisterFunctions(false, blockchain.StoreFuncNames.RollbackTransactions, store.rollbackTransactions)
 
 	return store, nil
 }
@@ -51,21 +62,204 @@ func (c *IDChainStore) persistTransactions(batch database.Batch, b *types.Block)
 			}
 			c.PersistMainchainTx(batch, *hash)
 		}
+	}
+	return c.persistIdentificationIndexes(batch, b)
+}
 
+// persistIdentificationIndexes writes the identification indexes of the
+// block, along with the undo record to roll them back.
+func (c *IDChainStore) persistIdentificationIndexes(batch database.Batch, b *types.Block) error {
+	ib := c.newIndexBatch(batch, b.Header.Height)
+	tree, err := c.newStateTree(ib, b.Header.Height)
+	if err != nil {
+		return err
+	}
+	txs := make(map[common.Uint256]*types.Transaction, len(b.Transactions))
+	for _, txn := range b.Transactions {
+		txs[txn.Hash()] = txn
+	}
+	for _, txn := range b.Transactions {
 		if txn.TxType == id.RegisterIdentification {
 			regPayload := txn.Payload.(*id.PayloadRegisterIdentification)
-			for _, content := range regPayload.Contents {
-				buf := new(bytes.Buffer)
-				buf.WriteString(regPayload.ID)
-				buf.WriteString(content.Path)
-				c.PersistRegisterIdentificationTx(batch, buf.Bytes(), txn.Hash())
+			if err := c.persistRegisterIdentification(ib, tree, regPayload,
+				txn.PayloadVersion, txn.Hash()); err != nil {
+				return err
+			}
+			if err := c.persistAddressIdentifications(ib, txs, txn,
+				[]string{regPayload.ID}); err != nil {
+				return err
+			}
+		}
+
+		if txn.TxType == id.RegisterIdentificationBatch {
+			batchPayload := txn.Payload.(*id.PayloadRegisterIdentificationBatch)
+			IDs := make([]string, 0, len(batchPayload.Entries))
+			for i := range batchPayload.Entries {
+				if err := c.persistRegisterIdentification(ib, tree, &batchPayload.Entries[i],
+					txn.PayloadVersion, txn.Hash()); err != nil {
+					return err
+				}
+				IDs = append(IDs, batchPayload.Entries[i].ID)
+			}
+			if err := c.persistAddressIdentifications(ib, txs, txn, IDs); err != nil {
+				return err
+			}
+		}
+
+		if txn.TxType == id.RegisterServiceEndpoint {
+			regPayload := txn.Payload.(*id.PayloadRegisterServiceEndpoint)
+			if err := c.persistIdentificationSequence(ib, regPayload.ID, regPayload.Sequence); err != nil {
+				return err
+			}
+			c.persistServiceEndpointTx(ib, regPayload.ID, txn.Hash())
+		}
+
+		if txn.TxType == id.TransferIdentification {
+			transferPayload := txn.Payload.(*id.PayloadTransferIdentification)
+			if err := c.persistIdentificationSequence(ib, transferPayload.ID,
+				transferPayload.Sequence); err != nil {
+				return err
+			}
+			if err := c.persistControllerRecord(ib, transferPayload.ID, ControllerRecord{
+				Controller: transferPayload.NewController,
+				TxHash:     txn.Hash(),
+				Height:     b.Header.Height,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if txn.TxType == id.SetRecoveryGuardians {
+			guardiansPayload := txn.Payload.(*id.PayloadSetRecoveryGuardians)
+			if err := c.persistIdentificationSequence(ib, guardiansPayload.ID,
+				guardiansPayload.Sequence); err != nil {
+				return err
+			}
+			c.persistRecoveryGuardiansTx(ib, guardiansPayload.ID, txn.Hash())
+		}
+
+		if txn.TxType == id.RecoverIdentification {
+			recoverPayload := txn.Payload.(*id.PayloadRecoverIdentification)
+			if err := c.persistIdentificationSequence(ib, recoverPayload.ID,
+				recoverPayload.Sequence); err != nil {
+				return err
+			}
+			guardians, err := c.getRecoveryGuardians(ib, txs, recoverPayload.ID)
+			if err != nil {
+				return err
+			}
+			if guardians == nil {
+				return errors.New("[IDChainStore], recover ID without guardians")
+			}
+			if err := c.persistPendingRecovery(ib, recoverPayload.ID, &PendingRecovery{
+				NewController: recoverPayload.NewController,
+				TxHash:        txn.Hash(),
+				Height:        b.Header.Height,
+				Delay:         guardians.Delay,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if txn.TxType == id.CancelRecovery {
+			cancelPayload := txn.Payload.(*id.PayloadCancelRecovery)
+			if err := c.persistIdentificationSequence(ib, cancelPayload.ID,
+				cancelPayload.Sequence); err != nil {
+				return err
+			}
+			c.cancelPendingRecovery(ib, cancelPayload.ID, txn.Hash())
+		}
+
+		if txn.TxType == id.Anchor {
+			anchorPayload := txn.Payload.(*id.PayloadAnchor)
+			for _, hash := range anchorPayload.Hashes {
+				c.persistAnchorTx(ib, hash, txn.Hash())
 			}
 		}
 	}
+
+	// The indexes above only follow transactions of the types the upgrades
+	// allow, maturing the recoveries is the only change made without one.
+	if c.activations.IsActive(params.UpgradeRecovery, b.Header.Height) {
+		if err := c.persistMaturedRecoveries(ib, b.Header.Height); err != nil {
+			return err
+		}
+	}
+	c.persistStateRoot(ib, tree)
+	if err := c.persistIdentificationChanges(ib, ib.changes); err != nil {
+		return err
+	}
+	if err := c.persistIdentificationStats(ib, b.Header.Timestamp); err != nil {
+		return err
+	}
+	if err := ib.commit(); err != nil {
+		return err
+	}
+
+	c.notifyIndexes(ib.keys(), b.Header.Previous, b.Hash())
 	return nil
 }
 
-func (c *IDChainStore) PersistRegisterIdentificationTx(batch database.Batch, idKey []byte, txHash common.Uint256) {
+func (c *IDChainStore) rollbackTransactions(batch database.Batch, b *types.Block) error {
+	for _, txn := range b.Transactions {
+		if err := c.RollbackTransaction(batch, txn); err != nil {
+			return err
+		}
+
+		if txn.TxType == types.RegisterAsset {
+			if err := c.RollbackAsset(batch, txn.Hash()); err != nil {
+				return err
+			}
+		}
+
+		if txn.TxType == types.RechargeToSideChain {
+			rechargePayload := txn.Payload.(*types.PayloadRechargeToSideChain)
+			hash, err := rechargePayload.GetMainchainTxHash(txn.PayloadVersion)
+			if err != nil {
+				return err
+			}
+			c.RollbackMainchainTx(batch, *hash)
+		}
+	}
+	keys, err := c.rollbackIndexes(batch, b.Header.Height)
+	if err != nil {
+		return err
+	}
+
+	c.notifyIndexes(keys, b.Hash(), b.Header.Previous)
+	return nil
+}
+
+// persistRegisterIdentification indexes every path registered for the ID to
+// the registering transaction, and sets the path in the state tree, if it is
+// built, to the hash of the registered content.
+func (c *IDChainStore) persistRegisterIdentification(batch *indexBatch, tree *stateTree,
+	regPayload *id.PayloadRegisterIdentification, version byte, txHash common.Uint256) error {
+	for _, content := range regPayload.Contents {
+		if err := c.countRegistration(batch, regPayload.ID, content.Path, len(content.Values)); err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		buf.WriteString(regPayload.ID)
+		buf.WriteString(content.Path)
+		c.persistRegisterIdentificationTx(batch, buf.Bytes(), txHash)
+		c.persistRegistrationHeight(batch, buf.Bytes())
+		c.persistIdentificationPath(batch, regPayload.ID, content.Path, txHash)
+		batch.change(ChangePath, regPayload.ID, content.Path, txHash)
+
+		if tree == nil {
+			continue
+		}
+		key := StateKey(regPayload.ID, content.Path)
+		if err := tree.Update(key, content.Hash(version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *IDChainStore) persistRegisterIdentificationTx(batch *indexBatch, idKey []byte, txHash common.Uint256) {
 	key := []byte{byte(blockchain.IX_Identification)}
 	key = append(key, idKey...)
 
@@ -82,3 +276,99 @@ func (c *IDChainStore) GetRegisterIdentificationTx(idKey []byte) ([]byte, error)
 
 	return data, nil
 }
+
+func (c *IDChainStore) persistRegistrationHeight(batch *indexBatch, idKey []byte) {
+	key := []byte{byte(IX_IdentificationHeight)}
+	key = append(key, idKey...)
+
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, batch.height)
+	batch.Put(key, data)
+}
+
+// GetRegistrationHeight returns the height of the block that last registered
+// the path of the ID. Paths registered before the node maintained the height
+// index have no height until the identification indexes are rebuilt.
+func (c *IDChainStore) GetRegistrationHeight(ID string, path string) (uint32, error) {
+	key := []byte{byte(IX_IdentificationHeight)}
+	key = append(key, ID...)
+	key = append(key, path...)
+	data, err := c.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, errors.New("[IDChainStore], invalid registration height.")
+	}
+	return binary.BigEndian.Uint32(data), nil
+}
+
+// identificationPathKey returns the key of the path of the ID in the path
+// index, the ID is prefixed with its length.
+func identificationPathKey(ID string, path string) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(IX_IdentificationPath)
+	common.WriteVarString(buf, ID)
+	buf.WriteString(path)
+	return buf.Bytes()
+}
+
+func (c *IDChainStore) persistIdentificationPath(batch *indexBatch, ID string, path string,
+	txHash common.Uint256) {
+	batch.Put(identificationPathKey(ID, path), txHash.Bytes())
+}
+
+// ForEachIdentificationPath calls fn with every registered path of the IDs
+// ordered after the ID after, in the order of the ID length, the ID and then
+// the path, until fn returns false. An empty after starts from the first ID.
+// The paths registered before the node maintained the path index are listed
+// once the identification indexes are rebuilt.
+func (c *IDChainStore) ForEachIdentificationPath(after string,
+	fn func(ID string, path string, txHash common.Uint256) bool) error {
+	prefix := []byte{byte(IX_IdentificationPath)}
+	iter := c.NewIterator(prefix)
+	defer iter.Release()
+
+	for ok := iter.Seek(identificationPathKey(after, "")); ok; ok = iter.Next() {
+		r := bytes.NewReader(iter.Key()[1:])
+		ID, err := common.ReadVarString(r)
+		if err != nil {
+			return errors.New("[IDChainStore], invalid identification path key.")
+		}
+		if ID == after {
+			continue
+		}
+		path := make([]byte, r.Len())
+		r.Read(path)
+
+		txHash, err := common.Uint256FromBytes(iter.Value())
+		if err != nil {
+			return err
+		}
+		if !fn(ID, string(path), *txHash) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (c *IDChainStore) persistServiceEndpointTx(batch *indexBatch, id string, txHash common.Uint256) {
+	key := []byte{byte(IX_ServiceEndpoint)}
+	key = append(key, id...)
+
+	batch.Put(key, txHash.Bytes())
+	batch.change(ChangeServiceEndpoints, id, "", txHash)
+}
+
+// GetServiceEndpointTx returns the hash of the transaction that last
+// registered the service endpoints of the ID.
+func (c *IDChainStore) GetServiceEndpointTx(id string) ([]byte, error) {
+	key := []byte{byte(IX_ServiceEndpoint)}
+	data, err := c.Get(append(key, id...))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
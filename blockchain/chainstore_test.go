@@ -0,0 +1,118 @@
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// testBlockInterval is the number of seconds between two test blocks.
+const testBlockInterval = 120
+
+// newTestChainStore returns a chain store of the regression test network in
+// a temporary folder, and the function removing it.
+func newTestChainStore(t *testing.T) (*IDChainStore, func()) {
+	dir, err := ioutil.TempDir("", "idchainstore")
+	if err != nil {
+		t.Fatal("create data folder error:", err)
+	}
+	store, err := NewChainStore(params.RegNetGenesisBlock, params.RegNetActivations, nil, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("open chain store error:", err)
+	}
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// saveTestBlock saves a block with the transactions on top of the best block.
+func saveTestBlock(t *testing.T, store *IDChainStore, txs ...*types.Transaction) *types.Block {
+	height := store.GetHeight() + 1
+	block := &types.Block{
+		Header: types.Header{
+			Previous:  store.GetCurrentBlockHash(),
+			Timestamp: params.RegNetGenesisBlock.Header.Timestamp + height*testBlockInterval,
+			Height:    height,
+		},
+		Transactions: txs,
+	}
+	if err := store.SaveBlock(block); err != nil {
+		t.Fatalf("save block %d error: %s", height, err)
+	}
+	return block
+}
+
+// registerTx returns a transaction registering the paths of the ID, with one
+// value for each path.
+func registerTx(ID string, paths ...string) *types.Transaction {
+	payload := &id.PayloadRegisterIdentification{ID: ID}
+	for _, path := range paths {
+		payload.Contents = append(payload.Contents, id.RegisterIdentificationContent{
+			Path:   path,
+			Values: []id.RegisterIdentificationValue{{DataHash: common.Uint256{byte(len(path))}}},
+		})
+	}
+	return &types.Transaction{
+		TxType:         id.RegisterIdentification,
+		PayloadVersion: id.RegisterIdentificationVersion1,
+		Payload:        payload,
+	}
+}
+
+// getRegisterTx returns the hash of the transaction which last registered
+// the path of the ID.
+func getRegisterTx(store *IDChainStore, ID, path string) (common.Uint256, error) {
+	data, err := store.GetRegisterIdentificationTx([]byte(ID + path))
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	hash, err := common.Uint256FromBytes(data)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	return *hash, nil
+}
+
+func TestForEachIdentificationPath(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const short = "did:short"
+
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	// IDs which are not addresses are stored before the ID address upgrade.
+	long := registerTx(ID, "kyc/person/phone")
+	saveTestBlock(t, store, registerTx(short, "kyc/person/identityCard", "social/twitter"), long)
+
+	list := func(after string) []string {
+		var paths []string
+		err := store.ForEachIdentificationPath(after, func(ID string, path string, txHash common.Uint256) bool {
+			paths = append(paths, ID+" "+path)
+			return true
+		})
+		if err != nil {
+			t.Fatal("identification paths error:", err)
+		}
+		return paths
+	}
+
+	paths := list("")
+	if len(paths) != 3 || paths[0] != short+" kyc/person/identityCard" ||
+		paths[1] != short+" social/twitter" || paths[2] != ID+" kyc/person/phone" {
+		t.Errorf("identification paths %q", paths)
+	}
+	if paths = list(short); len(paths) != 1 || paths[0] != ID+" kyc/person/phone" {
+		t.Errorf("identification paths after the short ID %q", paths)
+	}
+	if paths = list(ID); len(paths) != 0 {
+		t.Errorf("identification paths after the last ID %q", paths)
+	}
+}
@@ -0,0 +1,147 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// Types of identification changes.
+const (
+	// ChangePath is the registration of a path of an ID.
+	ChangePath byte = 0x00
+
+	// ChangeServiceEndpoints is the registration of the service endpoints of
+	// an ID.
+	ChangeServiceEndpoints byte = 0x01
+
+	// ChangeController is the change of the controller of an ID, by a
+	// transfer or at the end of a recovery.
+	ChangeController byte = 0x02
+
+	// ChangeRecovery is a change of the recovery guardians of an ID, or the
+	// start or cancel of a recovery.
+	ChangeRecovery byte = 0x03
+)
+
+// IdentificationChange is a change of an ID made by a block. Path is only
+// set for ChangePath changes.
+type IdentificationChange struct {
+	Type   byte
+	ID     string
+	Path   string
+	TxHash common.Uint256
+}
+
+func (c *IdentificationChange) Serialize(w io.Writer) error {
+	if err := common.WriteUint8(w, c.Type); err != nil {
+		return errors.New("[IdentificationChange], Type serialize failed.")
+	}
+
+	if err := common.WriteVarString(w, c.ID); err != nil {
+		return errors.New("[IdentificationChange], ID serialize failed.")
+	}
+
+	if err := common.WriteVarString(w, c.Path); err != nil {
+		return errors.New("[IdentificationChange], Path serialize failed.")
+	}
+
+	if err := c.TxHash.Serialize(w); err != nil {
+		return errors.New("[IdentificationChange], TxHash serialize failed.")
+	}
+
+	return nil
+}
+
+func (c *IdentificationChange) Deserialize(r io.Reader) error {
+	var err error
+	c.Type, err = common.ReadUint8(r)
+	if err != nil {
+		return errors.New("[IdentificationChange], Type deserialize failed.")
+	}
+
+	c.ID, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[IdentificationChange], ID deserialize failed.")
+	}
+
+	c.Path, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[IdentificationChange], Path deserialize failed.")
+	}
+
+	if err := c.TxHash.Deserialize(r); err != nil {
+		return errors.New("[IdentificationChange], TxHash deserialize failed.")
+	}
+
+	return nil
+}
+
+// persistIdentificationChanges saves the changes made by the block.
+func (c *IDChainStore) persistIdentificationChanges(batch *indexBatch, changes []IdentificationChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := common.WriteVarUint(buf, uint64(len(changes))); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := change.Serialize(buf); err != nil {
+			return err
+		}
+	}
+
+	batch.Put(heightKey(IX_IdentificationChanges, batch.height), buf.Bytes())
+	return nil
+}
+
+// ForEachIdentificationChanges calls fn with the changes of every block from
+// start to end which changed any ID, in height order, until fn returns false.
+func (c *IDChainStore) ForEachIdentificationChanges(start, end uint32,
+	fn func(height uint32, changes []IdentificationChange) bool) error {
+	iter := c.NewIterator([]byte{byte(IX_IdentificationChanges)})
+	defer iter.Release()
+
+	for ok := iter.Seek(heightKey(IX_IdentificationChanges, start)); ok; ok = iter.Next() {
+		key := iter.Key()
+		if len(key) != 5 {
+			continue
+		}
+		height := binary.BigEndian.Uint32(key[1:])
+		if height > end {
+			break
+		}
+
+		changes, err := deserializeIdentificationChanges(iter.Value())
+		if err != nil {
+			return err
+		}
+		if !fn(height, changes) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func deserializeIdentificationChanges(data []byte) ([]IdentificationChange, error) {
+	r := bytes.NewReader(data)
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, errors.New("[IDChainStore], identification changes deserialize failed.")
+	}
+
+	changes := make([]IdentificationChange, count)
+	for i := uint64(0); i < count; i++ {
+		if err := changes[i].Deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
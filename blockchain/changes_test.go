@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestIdentificationChangeSerialize(t *testing.T) {
+	change := IdentificationChange{
+		Type:   ChangePath,
+		ID:     "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+		Path:   "kyc/person/identityCard",
+		TxHash: common.Uint256{1, 2, 3},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := change.Serialize(buf); err != nil {
+		t.Fatal("serialize error:", err)
+	}
+	var decoded IdentificationChange
+	if err := decoded.Deserialize(buf); err != nil {
+		t.Fatal("deserialize error:", err)
+	}
+	if decoded != change {
+		t.Error("deserialized change differs!")
+	}
+}
+
+func TestIdentificationChanges(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	first := registerTx(ID, "kyc/person/identityCard", "kyc/person/phone")
+	saveTestBlock(t, store, first)
+	saveTestBlock(t, store)
+	second := registerTx(other, "kyc/person/phone")
+	block := saveTestBlock(t, store, second)
+
+	changes := make(map[uint32][]IdentificationChange)
+	err := store.ForEachIdentificationChanges(0, store.GetHeight(),
+		func(height uint32, blockChanges []IdentificationChange) bool {
+			changes[height] = blockChanges
+			return true
+		})
+	if err != nil {
+		t.Fatal("changes error:", err)
+	}
+
+	// Blocks without any change of an ID have no record.
+	if len(changes) != 2 {
+		t.Fatalf("%d blocks with changes, expected 2", len(changes))
+	}
+	expected := []IdentificationChange{
+		{Type: ChangePath, ID: ID, Path: "kyc/person/identityCard", TxHash: first.Hash()},
+		{Type: ChangePath, ID: ID, Path: "kyc/person/phone", TxHash: first.Hash()},
+	}
+	if len(changes[1]) != len(expected) {
+		t.Fatalf("%d changes at height 1, expected %d", len(changes[1]), len(expected))
+	}
+	for i, change := range changes[1] {
+		if change != expected[i] {
+			t.Errorf("change %d at height 1 is %v, expected %v", i, change, expected[i])
+		}
+	}
+	if len(changes[3]) != 1 || changes[3][0].ID != other || !changes[3][0].TxHash.IsEqual(second.Hash()) {
+		t.Error("changes at height 3 error!")
+	}
+
+	// The range stops where fn returns false.
+	var heights []uint32
+	store.ForEachIdentificationChanges(0, store.GetHeight(),
+		func(height uint32, blockChanges []IdentificationChange) bool {
+			heights = append(heights, height)
+			return false
+		})
+	if len(heights) != 1 || heights[0] != 1 {
+		t.Error("changes range should stop after the first block!")
+	}
+
+	// A rolled back block takes its changes with it.
+	if err := store.RollbackBlock(block.Hash()); err != nil {
+		t.Fatal("rollback error:", err)
+	}
+	heights = nil
+	store.ForEachIdentificationChanges(0, 3,
+		func(height uint32, blockChanges []IdentificationChange) bool {
+			heights = append(heights, height)
+			return true
+		})
+	if len(heights) != 1 || heights[0] != 1 {
+		t.Error("rolled back block should have no changes!")
+	}
+}
@@ -0,0 +1,39 @@
+package blockchain
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+)
+
+// checkCheckpoint checks that the block is the checkpoint block when there is
+// a checkpoint at its height, and that it does not fork the chain at or below
+// the highest checkpoint the chain has reached. It runs when the block is
+// accepted, before the chain is reorganized, so the chain is never rolled
+// back past a checkpoint.
+func (c *IDChainStore) checkCheckpoint(b *types.Block) error {
+	height := b.Header.Height
+	if checkpoint, ok := c.checkpoints.Get(height); ok {
+		if hash := b.Hash(); !hash.IsEqual(checkpoint.Hash) {
+			return errors.New("[IDChainStore], block at height " +
+				strconv.FormatUint(uint64(height), 10) + " does not match the checkpoint")
+		}
+	}
+
+	// The block on top of the best block has a height above it, any other
+	// block forks the chain below its own height.
+	if reached := c.checkpoints.Reached(c.GetHeight()); reached != nil && height <= reached.Height {
+		return errors.New("[IDChainStore], block at height " +
+			strconv.FormatUint(uint64(height), 10) + " forks the chain below the checkpoint at height " +
+			strconv.FormatUint(uint64(reached.Height), 10))
+	}
+	return nil
+}
+
+// IsBelowLastCheckpoint returns whether the height is not above the highest
+// checkpoint. The blocks up to it are known to be valid once the chain
+// reaches the checkpoint.
+func (c *IDChainStore) IsBelowLastCheckpoint(height uint32) bool {
+	return c.checkpoints.IsBelowLast(height)
+}
@@ -0,0 +1,42 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+)
+
+func TestCheckCheckpoint(t *testing.T) {
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	first := saveTestBlock(t, store)
+	store.checkpoints = params.Checkpoints{
+		{Height: 1, Hash: first.Hash()},
+		{Height: 3, Hash: first.Hash()},
+	}
+	fork := func(height uint32) *types.Block {
+		return &types.Block{Header: types.Header{Height: height, Nonce: 1}}
+	}
+
+	// A fork below the checkpoint the chain has reached is rejected before
+	// the chain is rolled back to it.
+	if err := store.CheckBlockContext(fork(1)); err == nil {
+		t.Error("fork at the checkpoint should be rejected!")
+	}
+	if err := store.CheckBlockContext(fork(2)); err != nil {
+		t.Error("fork above the checkpoint error:", err)
+	}
+
+	saveTestBlock(t, store)
+	if err := store.CheckBlockContext(fork(2)); err != nil {
+		t.Error("fork above the checkpoint error:", err)
+	}
+
+	// A block at a checkpoint the chain has not reached yet must match it.
+	if err := store.CheckBlockContext(fork(3)); err == nil {
+		t.Error("block not matching the checkpoint should be rejected!")
+	}
+}
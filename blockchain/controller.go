@@ -0,0 +1,121 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// ControllerRecord records a change of the controller of an ID.
+type ControllerRecord struct {
+	Controller string
+	TxHash     common.Uint256
+	Height     uint32
+}
+
+func (r *ControllerRecord) Serialize(w io.Writer) error {
+	if err := common.WriteVarString(w, r.Controller); err != nil {
+		return errors.New("[ControllerRecord], Controller serialize failed.")
+	}
+
+	if err := r.TxHash.Serialize(w); err != nil {
+		return errors.New("[ControllerRecord], TxHash serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, r.Height); err != nil {
+		return errors.New("[ControllerRecord], Height serialize failed.")
+	}
+
+	return nil
+}
+
+func (r *ControllerRecord) Deserialize(reader io.Reader) error {
+	var err error
+	r.Controller, err = common.ReadVarString(reader)
+	if err != nil {
+		return errors.New("[ControllerRecord], Controller deserialize failed.")
+	}
+
+	if err := r.TxHash.Deserialize(reader); err != nil {
+		return errors.New("[ControllerRecord], TxHash deserialize failed.")
+	}
+
+	r.Height, err = common.ReadUint32(reader)
+	if err != nil {
+		return errors.New("[ControllerRecord], Height deserialize failed.")
+	}
+
+	return nil
+}
+
+func (c *IDChainStore) persistControllerRecord(batch *indexBatch, id string, record ControllerRecord) error {
+	key := []byte{byte(IX_IdentificationController)}
+	key = append(key, id...)
+
+	var records []ControllerRecord
+	if data, err := batch.Get(key); err == nil {
+		if records, err = deserializeControllerRecords(data); err != nil {
+			return err
+		}
+	}
+	records = append(records, record)
+
+	buf := new(bytes.Buffer)
+	if err := common.WriteVarUint(buf, uint64(len(records))); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := r.Serialize(buf); err != nil {
+			return err
+		}
+	}
+
+	batch.Put(key, buf.Bytes())
+	batch.change(ChangeController, id, "", record.TxHash)
+	return nil
+}
+
+// GetIdentificationControllers returns the controller changes of the ID,
+// oldest first. An ID that has never been transferred has no records.
+func (c *IDChainStore) GetIdentificationControllers(id string) ([]ControllerRecord, error) {
+	key := []byte{byte(IX_IdentificationController)}
+	data, err := c.Get(append(key, id...))
+	if err != nil {
+		return nil, nil
+	}
+
+	return deserializeControllerRecords(data)
+}
+
+// GetIdentificationController returns the ID controlling the given ID, which is
+// the ID itself unless it has been transferred.
+func (c *IDChainStore) GetIdentificationController(id string) (string, error) {
+	records, err := c.GetIdentificationControllers(id)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return id, nil
+	}
+
+	return records[len(records)-1].Controller, nil
+}
+
+func deserializeControllerRecords(data []byte) ([]ControllerRecord, error) {
+	r := bytes.NewReader(data)
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, errors.New("[IDChainStore], controller records deserialize failed.")
+	}
+
+	records := make([]ControllerRecord, count)
+	for i := uint64(0); i < count; i++ {
+		if err := records[i].Deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
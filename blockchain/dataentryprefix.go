@@ -0,0 +1,79 @@
+package blockchain
+
+// Data entry prefixes of the indexes maintained by IDChainStore on top of the
+// side chain store. They are picked from a range the side chain store does
+// not use.
+const (
+	// IX_ServiceEndpoint maps an ID to the hash of the transaction that last
+	// registered its service endpoints.
+	IX_ServiceEndpoint = 0xa0
+
+	// IX_IdentificationUndo maps a block height to the previous values of the
+	// identification index entries changed by the block at that height.
+	IX_IdentificationUndo = 0xa1
+
+	// IX_IdentificationController maps an ID to the chain of controllers the
+	// ID has been transferred to.
+	IX_IdentificationController = 0xa2
+
+	// IX_RecoveryGuardians maps an ID to the hash of the transaction that last
+	// set its recovery guardians.
+	IX_RecoveryGuardians = 0xa3
+
+	// IX_PendingRecovery maps an ID to its pending recovery.
+	IX_PendingRecovery = 0xa4
+
+	// IX_RecoveryMaturity maps a block height to the IDs whose recovery
+	// takes effect at the end of the block.
+	IX_RecoveryMaturity = 0xa5
+
+	// IX_Anchor maps an anchored hash to the hash of the transaction that
+	// first anchored it.
+	IX_Anchor = 0xa6
+
+	// IX_StateNode maps the hash of a node of the identification state tree
+	// to the node.
+	IX_StateNode = 0xa7
+
+	// IX_StateRoot maps a block height to the root of the identification
+	// state tree after the block at that height.
+	IX_StateRoot = 0xa8
+
+	// IX_ReindexProgress holds the progress of an unfinished rebuild of the
+	// identification indexes.
+	IX_ReindexProgress = 0xa9
+
+	// IX_IdentificationChanges maps a block height to the changes of IDs
+	// made by the block at that height.
+	IX_IdentificationChanges = 0xaa
+
+	// IX_IdentificationStats holds the counters of the identification
+	// registry.
+	IX_IdentificationStats = 0xab
+
+	// IX_DailyRegistrations maps a day to the number of paths registered in
+	// the blocks of the day.
+	IX_DailyRegistrations = 0xac
+
+	// IX_IdentificationPathCount maps an ID to the number of its registered
+	// paths.
+	IX_IdentificationPathCount = 0xad
+
+	// IX_AddressIdentification maps an address followed by an ID registered
+	// by a transaction spending from the address to the hash of the last
+	// such transaction.
+	IX_AddressIdentification = 0xae
+
+	// IX_IdentificationSequence maps an ID to its sequence number.
+	IX_IdentificationSequence = 0xaf
+
+	// IX_IdentificationHeight maps an ID followed by a path to the height
+	// of the block that last registered the path.
+	IX_IdentificationHeight = 0xb0
+
+	// IX_IdentificationPath maps an ID, prefixed with its length, followed
+	// by a path to the hash of the transaction that last registered the
+	// path. Unlike the keys of the register index, its keys tell the ID
+	// from the path whatever the length of the ID.
+	IX_IdentificationPath = 0xb1
+)
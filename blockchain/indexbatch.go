@@ -0,0 +1,185 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.SideChain/database"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// maxUndoDataSize is the maximum size of a key or value in an undo record.
+const maxUndoDataSize = 16 * 1024 * 1024
+
+// undoEntry is the value an index entry had before a block changed it.
+type undoEntry struct {
+	Key    []byte
+	Exists bool
+	Value  []byte
+}
+
+// indexBatch collects the identification index changes of a block. It serves
+// reads of entries written earlier in the same block, and remembers the
+// previous value of every entry it changes so the block can be rolled back.
+// It also collects the changes of IDs made by the block for the change feed,
+// and the registry stats of the block.
+type indexBatch struct {
+	batch   database.Batch
+	store   *IDChainStore
+	height  uint32
+	pending map[string][]byte
+	undo    []undoEntry
+	changes []IdentificationChange
+	stats   IdentificationStats
+}
+
+func (c *IDChainStore) newIndexBatch(batch database.Batch, height uint32) *indexBatch {
+	return &indexBatch{
+		batch:   batch,
+		store:   c,
+		height:  height,
+		pending: make(map[string][]byte),
+	}
+}
+
+// Get returns the value of the key as of the changes made so far in the block.
+func (b *indexBatch) Get(key []byte) ([]byte, error) {
+	if value, ok := b.pending[string(key)]; ok {
+		if value == nil {
+			return nil, errors.New("not found")
+		}
+		return value, nil
+	}
+	return b.store.Get(key)
+}
+
+func (b *indexBatch) Put(key []byte, value []byte) {
+	b.record(key)
+	b.pending[string(key)] = value
+	b.batch.Put(key, value)
+}
+
+func (b *indexBatch) Delete(key []byte) {
+	b.record(key)
+	b.pending[string(key)] = nil
+	b.batch.Delete(key)
+}
+
+// change records a change of an ID made by the block.
+func (b *indexBatch) change(changeType byte, ID string, path string, txHash common.Uint256) {
+	b.changes = append(b.changes, IdentificationChange{
+		Type:   changeType,
+		ID:     ID,
+		Path:   path,
+		TxHash: txHash,
+	})
+}
+
+// record saves the value the key had before the block, only the first change
+// of a key in the block is recorded.
+func (b *indexBatch) record(key []byte) {
+	if _, ok := b.pending[string(key)]; ok {
+		return
+	}
+
+	entry := undoEntry{Key: key}
+	if value, err := b.store.Get(key); err == nil {
+		entry.Exists = true
+		entry.Value = value
+	}
+	b.undo = append(b.undo, entry)
+}
+
+// keys returns the keys of the entries changed by the block.
+func (b *indexBatch) keys() [][]byte {
+	keys := make([][]byte, 0, len(b.undo))
+	for _, entry := range b.undo {
+		keys = append(keys, entry.Key)
+	}
+	return keys
+}
+
+// commit writes the undo record of the block into the batch.
+func (b *indexBatch) commit() error {
+	if len(b.undo) == 0 {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := common.WriteVarUint(buf, uint64(len(b.undo))); err != nil {
+		return err
+	}
+	for _, entry := range b.undo {
+		if err := common.WriteVarBytes(buf, entry.Key); err != nil {
+			return err
+		}
+		if err := common.WriteUint8(buf, boolToByte(entry.Exists)); err != nil {
+			return err
+		}
+		if err := common.WriteVarBytes(buf, entry.Value); err != nil {
+			return err
+		}
+	}
+
+	b.batch.Put(heightKey(IX_IdentificationUndo, b.height), buf.Bytes())
+	return nil
+}
+
+// rollbackIndexes restores the identification index entries changed by the
+// block at the given height, and returns their keys.
+func (c *IDChainStore) rollbackIndexes(batch database.Batch, height uint32) ([][]byte, error) {
+	key := heightKey(IX_IdentificationUndo, height)
+	data, err := c.Get(key)
+	if err != nil {
+		// The block did not change any identification index.
+		return nil, nil
+	}
+
+	r := bytes.NewReader(data)
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, errors.New("[IDChainStore], undo record deserialize failed.")
+	}
+	keys := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		k, err := common.ReadVarBytes(r, maxUndoDataSize, "undo key")
+		if err != nil {
+			return nil, errors.New("[IDChainStore], undo key deserialize failed.")
+		}
+		exists, err := common.ReadUint8(r)
+		if err != nil {
+			return nil, errors.New("[IDChainStore], undo flag deserialize failed.")
+		}
+		v, err := common.ReadVarBytes(r, maxUndoDataSize, "undo value")
+		if err != nil {
+			return nil, errors.New("[IDChainStore], undo value deserialize failed.")
+		}
+
+		if exists != 0 {
+			batch.Put(k, v)
+		} else {
+			batch.Delete(k)
+		}
+		keys = append(keys, k)
+	}
+
+	batch.Delete(key)
+	return keys, nil
+}
+
+// heightKey returns the key of a per height entry, heights are big endian so
+// entries iterate in height order.
+func heightKey(prefix byte, height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = prefix
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
+
+func boolToByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// indexChange is a change of the identification indexes by a block which
+// may not be committed yet, tip is the hash of the best block once it is.
+type indexChange struct {
+	keys [][]byte
+	tip  common.Uint256
+}
+
+// indexListeners tracks the changes of the identification indexes for the
+// caches built on top of them. The store functions run before the block is
+// committed, and the commit may fail, so the changes are held until the
+// store reaches the block they were made for.
+type indexListeners struct {
+	sync.Mutex
+	pending   []indexChange
+	listeners []func(keys [][]byte)
+}
+
+// RegisterIndexListener registers fn to be called with the keys of the
+// identification index entries changed by every persisted or rolled back
+// block. It is called once the block is committed, by the next call to
+// SyncIndexListeners or the next persisted or rolled back block.
+func (c *IDChainStore) RegisterIndexListener(fn func(keys [][]byte)) {
+	c.listeners.Lock()
+	c.listeners.listeners = append(c.listeners.listeners, fn)
+	c.listeners.Unlock()
+}
+
+// SyncIndexListeners calls the listeners with the changes of the committed
+// blocks. A reader of a cache built on the indexes calls it before every
+// read, so it never reads an entry changed by a committed block.
+func (c *IDChainStore) SyncIndexListeners() {
+	tip := c.GetCurrentBlockHash()
+
+	c.listeners.Lock()
+	defer c.listeners.Unlock()
+
+	c.syncIndexListeners(tip)
+}
+
+// syncIndexListeners calls the listeners with the changes up to the last
+// change made for the best block tip, the changes of a block whose commit
+// failed go with them. It must be called with the listeners locked.
+func (c *IDChainStore) syncIndexListeners(tip common.Uint256) {
+	committed := -1
+	for i, change := range c.listeners.pending {
+		if change.tip.IsEqual(tip) {
+			committed = i
+		}
+	}
+	if committed < 0 {
+		return
+	}
+
+	changes := c.listeners.pending[:committed+1]
+	c.listeners.pending = append([]indexChange(nil), c.listeners.pending[committed+1:]...)
+	for _, change := range changes {
+		for _, listener := range c.listeners.listeners {
+			listener(change.keys)
+		}
+	}
+}
+
+// notifyIndexes holds the changed keys for the listeners until the best
+// block is tip. The blocks are persisted one after the other on top of the
+// best block from, so the changes committed up to from are passed on first.
+func (c *IDChainStore) notifyIndexes(keys [][]byte, from, tip common.Uint256) {
+	c.listeners.Lock()
+	defer c.listeners.Unlock()
+
+	c.syncIndexListeners(from)
+	if len(c.listeners.listeners) == 0 {
+		return
+	}
+	c.listeners.pending = append(c.listeners.pending, indexChange{keys: keys, tip: tip})
+}
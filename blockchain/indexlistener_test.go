@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain/blockchain"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestNotifyIndexes(t *testing.T) {
+	var notified [][]byte
+	store := new(IDChainStore)
+	store.RegisterIndexListener(func(keys [][]byte) {
+		notified = append(notified, keys...)
+	})
+
+	a, b, c, d := common.Uint256{1}, common.Uint256{2}, common.Uint256{3}, common.Uint256{4}
+	store.notifyIndexes([][]byte{{1}}, a, b)
+	if len(notified) != 0 {
+		t.Fatal("changes should not be notified before the commit!")
+	}
+
+	// The next block is persisted on top of b, so b is committed.
+	store.notifyIndexes([][]byte{{2}}, b, c)
+	if len(notified) != 1 || notified[0][0] != 1 {
+		t.Fatal("changes of a committed block should be notified!")
+	}
+
+	// The commit of c failed, another block is persisted on top of b.
+	store.notifyIndexes([][]byte{{3}}, b, d)
+	if len(notified) != 1 {
+		t.Fatal("changes of a block whose commit failed should not be notified!")
+	}
+
+	// Once d is committed, the changes of c go with its own.
+	store.notifyIndexes([][]byte{{4}}, d, a)
+	if len(notified) != 3 || notified[1][0] != 2 || notified[2][0] != 3 {
+		t.Fatal("changes of a failed block should be notified with the next committed one!")
+	}
+}
+
+func TestIndexListenerRollback(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const path = "kyc/person/identityCard"
+
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	var notified [][]byte
+	store.RegisterIndexListener(func(keys [][]byte) {
+		notified = append(notified, keys...)
+	})
+	changed := func() bool {
+		key := append([]byte{byte(blockchain.IX_Identification)}, ID+path...)
+		for _, k := range notified {
+			if bytes.Equal(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	block := saveTestBlock(t, store, registerTx(ID, path))
+	store.SyncIndexListeners()
+	if !changed() {
+		t.Fatal("registered path should be notified once committed!")
+	}
+
+	notified = nil
+	if err := store.RollbackBlock(block.Hash()); err != nil {
+		t.Fatal("rollback error:", err)
+	}
+	store.SyncIndexListeners()
+	if !changed() {
+		t.Error("rolled back path should be notified once committed!")
+	}
+}
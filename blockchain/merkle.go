@@ -0,0 +1,129 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// MerkleProof proves that a transaction is included in a block. Branch holds
+// the sibling hashes from the transaction up to the merkle root, and the bits
+// of Index tell whether the node is the left or the right one at each level.
+// TxCount is the number of transactions of the block, which gives the shape
+// of the tree.
+type MerkleProof struct {
+	Index   uint32
+	TxCount uint32
+	Branch  []common.Uint256
+}
+
+// GetMerkleProof returns the proof of the transaction with the hash in the
+// block, the merkle tree is built the same way as the block merkle root, the
+// last node of a level with an odd number of nodes is paired with itself.
+func GetMerkleProof(block *types.Block, txHash common.Uint256) (*MerkleProof, error) {
+	hashes := make([]common.Uint256, 0, len(block.Transactions))
+	index := -1
+	for i, txn := range block.Transactions {
+		hash := txn.Hash()
+		if hash.IsEqual(txHash) {
+			index = i
+		}
+		hashes = append(hashes, hash)
+	}
+	if index < 0 {
+		return nil, errors.New("transaction not found in block")
+	}
+
+	proof := &MerkleProof{Index: uint32(index), TxCount: uint32(len(hashes))}
+	for len(hashes) > 1 {
+		if len(hashes)%2 == 1 {
+			hashes = append(hashes, hashes[len(hashes)-1])
+		}
+		proof.Branch = append(proof.Branch, hashes[index^1])
+
+		next := make([]common.Uint256, 0, len(hashes)/2)
+		for i := 0; i < len(hashes); i += 2 {
+			next = append(next, merkleParent(hashes[i], hashes[i+1]))
+		}
+		hashes = next
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// Root returns the merkle root the proof leads to from the transaction hash.
+// The index must be in the block and the branch must have one node per level
+// of the tree. A node is paired with an equal node only when it is the last
+// node of a level with an odd number of nodes, as the duplicated last node
+// would otherwise let the last transaction pass for one after it.
+func (p *MerkleProof) Root(txHash common.Uint256) (common.Uint256, error) {
+	if p.Index >= p.TxCount {
+		return common.Uint256{}, errors.New("transaction index is out of the block")
+	}
+
+	hash := txHash
+	index, count := p.Index, p.TxCount
+	level := 0
+	for ; count > 1; level++ {
+		if level >= len(p.Branch) {
+			return common.Uint256{}, errors.New("merkle branch is too short")
+		}
+		sibling := p.Branch[level]
+		paired := index == count-1 && count%2 == 1
+		if paired != sibling.IsEqual(hash) {
+			return common.Uint256{}, errors.New("merkle branch pairs a node with itself")
+		}
+
+		if index&1 == 0 {
+			hash = merkleParent(hash, sibling)
+		} else {
+			hash = merkleParent(sibling, hash)
+		}
+		index >>= 1
+		count = (count + 1) / 2
+	}
+	if level != len(p.Branch) {
+		return common.Uint256{}, errors.New("merkle branch is too long")
+	}
+
+	return hash, nil
+}
+
+// HeaderChain is a chain of headers trusted by a verifier, such as the
+// headers synced by a light client.
+type HeaderChain interface {
+	GetHeader(hash common.Uint256) (*types.Header, error)
+}
+
+// VerifyTransactionProof checks that the header is in the chain, and that the
+// proof leads from the transaction hash to the merkle root of the header.
+func VerifyTransactionProof(chain HeaderChain, header *types.Header, txHash common.Uint256, proof *MerkleProof) error {
+	trusted, err := chain.GetHeader(header.Hash())
+	if err != nil || trusted == nil {
+		return errors.New("header is not in the chain")
+	}
+	if !trusted.MerkleRoot.IsEqual(header.MerkleRoot) {
+		return errors.New("header does not match the chain")
+	}
+
+	root, err := proof.Root(txHash)
+	if err != nil {
+		return err
+	}
+	if !root.IsEqual(header.MerkleRoot) {
+		return errors.New("transaction is not included in the block")
+	}
+
+	return nil
+}
+
+func merkleParent(left, right common.Uint256) common.Uint256 {
+	var data [64]byte
+	copy(data[:32], left[:])
+	copy(data[32:], right[:])
+	once := sha256.Sum256(data[:])
+	return common.Uint256(sha256.Sum256(once[:]))
+}
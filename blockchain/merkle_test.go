@@ -0,0 +1,115 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+type headerChain map[common.Uint256]*types.Header
+
+func (c headerChain) GetHeader(hash common.Uint256) (*types.Header, error) {
+	header, ok := c[hash]
+	if !ok {
+		return nil, errors.New("header not found")
+	}
+	return header, nil
+}
+
+func merkleBlock(count int) *types.Block {
+	block := &types.Block{}
+	for i := 0; i < count; i++ {
+		block.Transactions = append(block.Transactions, &types.Transaction{
+			TxType:     types.TransferAsset,
+			Payload:    &types.PayloadTransferAsset{},
+			Attributes: []*types.Attribute{{Usage: types.Nonce, Data: []byte{byte(i)}}},
+		})
+	}
+	return block
+}
+
+func TestMerkleProof(t *testing.T) {
+	for count := 1; count <= 9; count++ {
+		block := merkleBlock(count)
+
+		var root common.Uint256
+		for i, txn := range block.Transactions {
+			proof, err := GetMerkleProof(block, txn.Hash())
+			if err != nil {
+				t.Fatalf("proof of tx %d of %d error: %s", i, count, err)
+			}
+			if proof.Index != uint32(i) || proof.TxCount != uint32(count) {
+				t.Errorf("proof of tx %d of %d has index %d of %d", i, count, proof.Index, proof.TxCount)
+			}
+			txRoot, err := proof.Root(txn.Hash())
+			if err != nil {
+				t.Fatalf("root of tx %d of %d error: %s", i, count, err)
+			}
+			if i == 0 {
+				root = txRoot
+			} else if !txRoot.IsEqual(root) {
+				t.Errorf("root of tx %d of %d differs from tx 0", i, count)
+			}
+		}
+	}
+
+	if _, err := GetMerkleProof(merkleBlock(3), merkleBlock(4).Transactions[3].Hash()); err == nil {
+		t.Error("proof of a tx out of the block should fail!")
+	}
+}
+
+func TestMerkleProofTampered(t *testing.T) {
+	block := merkleBlock(5)
+	last := block.Transactions[4].Hash()
+	proof, err := GetMerkleProof(block, last)
+	if err != nil {
+		t.Fatal("proof error:", err)
+	}
+	root, _ := proof.Root(last)
+
+	// The last tx of a level with an odd number of nodes is paired with
+	// itself, which must not let it pass for a sixth tx.
+	phantom := &MerkleProof{Index: 5, TxCount: 6, Branch: proof.Branch}
+	if _, err := phantom.Root(last); err == nil {
+		t.Error("proof of a duplicated leaf should fail!")
+	}
+	phantom = &MerkleProof{Index: 5, TxCount: 5, Branch: proof.Branch}
+	if _, err := phantom.Root(last); err == nil {
+		t.Error("proof with an index out of the block should fail!")
+	}
+
+	short := &MerkleProof{Index: 4, TxCount: 5, Branch: proof.Branch[:2]}
+	if _, err := short.Root(last); err == nil {
+		t.Error("proof with a short branch should fail!")
+	}
+	long := &MerkleProof{Index: 4, TxCount: 5, Branch: append(proof.Branch, root)}
+	if _, err := long.Root(last); err == nil {
+		t.Error("proof with a long branch should fail!")
+	}
+
+	first := block.Transactions[0].Hash()
+	proof, _ = GetMerkleProof(block, first)
+	header := &types.Header{MerkleRoot: root}
+	chain := headerChain{header.Hash(): header}
+	if err := VerifyTransactionProof(chain, header, first, proof); err != nil {
+		t.Error("verify proof error:", err)
+	}
+
+	tampered := &MerkleProof{Index: proof.Index, TxCount: proof.TxCount,
+		Branch: append([]common.Uint256{}, proof.Branch...)}
+	tampered.Branch[1][0] ^= 1
+	if err := VerifyTransactionProof(chain, header, first, tampered); err == nil {
+		t.Error("proof with a tampered branch should fail!")
+	}
+	swapped := &MerkleProof{Index: 1, TxCount: proof.TxCount, Branch: proof.Branch}
+	if err := VerifyTransactionProof(chain, header, first, swapped); err == nil {
+		t.Error("proof with a wrong index should fail!")
+	}
+
+	other := &types.Header{MerkleRoot: root, Height: 1}
+	if err := VerifyTransactionProof(chain, other, first, proof); err == nil {
+		t.Error("proof against a header out of the chain should fail!")
+	}
+}
@@ -0,0 +1,249 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// PendingRecovery is a recovery of an ID waiting for its delay to pass.
+type PendingRecovery struct {
+	NewController string
+	TxHash        common.Uint256
+	Height        uint32
+	Delay         uint32
+}
+
+// EffectiveHeight returns the height of the block at the end of which the
+// controller of the ID changes.
+func (p *PendingRecovery) EffectiveHeight() uint32 {
+	return p.Height + p.Delay
+}
+
+func (p *PendingRecovery) Serialize(w io.Writer) error {
+	if err := common.WriteVarString(w, p.NewController); err != nil {
+		return errors.New("[PendingRecovery], NewController serialize failed.")
+	}
+
+	if err := p.TxHash.Serialize(w); err != nil {
+		return errors.New("[PendingRecovery], TxHash serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Height); err != nil {
+		return errors.New("[PendingRecovery], Height serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Delay); err != nil {
+		return errors.New("[PendingRecovery], Delay serialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PendingRecovery) Deserialize(r io.Reader) error {
+	var err error
+	p.NewController, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[PendingRecovery], NewController deserialize failed.")
+	}
+
+	if err := p.TxHash.Deserialize(r); err != nil {
+		return errors.New("[PendingRecovery], TxHash deserialize failed.")
+	}
+
+	p.Height, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[PendingRecovery], Height deserialize failed.")
+	}
+
+	p.Delay, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[PendingRecovery], Delay deserialize failed.")
+	}
+
+	return nil
+}
+
+func (c *IDChainStore) persistRecoveryGuardiansTx(batch *indexBatch, ID string, txHash common.Uint256) {
+	key := []byte{byte(IX_RecoveryGuardians)}
+	key = append(key, ID...)
+
+	batch.Put(key, txHash.Bytes())
+	batch.change(ChangeRecovery, ID, "", txHash)
+}
+
+// GetRecoveryGuardians returns the guardians set for the ID, or nil if the ID
+// has no guardians.
+func (c *IDChainStore) GetRecoveryGuardians(ID string) (*id.PayloadSetRecoveryGuardians, error) {
+	return c.getRecoveryGuardians(c, nil, ID)
+}
+
+// getRecoveryGuardians reads the guardians of the ID through db. The
+// transactions of txs are not committed yet, so the guardians set by them are
+// looked up there first.
+func (c *IDChainStore) getRecoveryGuardians(db getter, txs map[common.Uint256]*types.Transaction,
+	ID string) (*id.PayloadSetRecoveryGuardians, error) {
+	key := []byte{byte(IX_RecoveryGuardians)}
+	data, err := db.Get(append(key, ID...))
+	if err != nil {
+		return nil, nil
+	}
+
+	txHash, err := common.Uint256FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	txn, ok := txs[*txHash]
+	if !ok {
+		if txn, _, err = c.GetTransaction(*txHash); err != nil {
+			return nil, err
+		}
+	}
+	guardians, ok := txn.Payload.(*id.PayloadSetRecoveryGuardians)
+	if !ok {
+		return nil, errors.New("[IDChainStore], invalid recovery guardians transaction.")
+	}
+	if len(guardians.Guardians) == 0 {
+		return nil, nil
+	}
+
+	return guardians, nil
+}
+
+// persistPendingRecovery starts the recovery of the ID, and schedules it to
+// take effect at the end of the block at its effective height.
+func (c *IDChainStore) persistPendingRecovery(batch *indexBatch, ID string, recovery *PendingRecovery) error {
+	key := []byte{byte(IX_PendingRecovery)}
+	key = append(key, ID...)
+
+	buf := new(bytes.Buffer)
+	if err := recovery.Serialize(buf); err != nil {
+		return err
+	}
+	batch.Put(key, buf.Bytes())
+	batch.change(ChangeRecovery, ID, "", recovery.TxHash)
+
+	maturityKey := heightKey(IX_RecoveryMaturity, recovery.EffectiveHeight())
+	var ids []string
+	if data, err := batch.Get(maturityKey); err == nil {
+		if ids, err = deserializeStrings(data); err != nil {
+			return err
+		}
+	}
+	ids = append(ids, ID)
+
+	buf = new(bytes.Buffer)
+	if err := serializeStrings(buf, ids); err != nil {
+		return err
+	}
+	batch.Put(maturityKey, buf.Bytes())
+
+	return nil
+}
+
+func (c *IDChainStore) cancelPendingRecovery(batch *indexBatch, ID string, txHash common.Uint256) {
+	key := []byte{byte(IX_PendingRecovery)}
+	key = append(key, ID...)
+
+	batch.Delete(key)
+	batch.change(ChangeRecovery, ID, "", txHash)
+}
+
+// persistMaturedRecoveries hands the IDs whose recovery takes effect at the
+// end of the block over to their new controllers.
+func (c *IDChainStore) persistMaturedRecoveries(batch *indexBatch, height uint32) error {
+	maturityKey := heightKey(IX_RecoveryMaturity, height)
+	data, err := batch.Get(maturityKey)
+	if err != nil {
+		return nil
+	}
+	ids, err := deserializeStrings(data)
+	if err != nil {
+		return err
+	}
+
+	for _, ID := range ids {
+		key := []byte{byte(IX_PendingRecovery)}
+		key = append(key, ID...)
+		recovery, err := getPendingRecovery(batch, key)
+		if err != nil {
+			return err
+		}
+
+		// The recovery has been cancelled, or replaced by a later one.
+		if recovery == nil || recovery.EffectiveHeight() != height {
+			continue
+		}
+
+		if err := c.persistControllerRecord(batch, ID, ControllerRecord{
+			Controller: recovery.NewController,
+			TxHash:     recovery.TxHash,
+			Height:     height,
+		}); err != nil {
+			return err
+		}
+		batch.Delete(key)
+	}
+
+	batch.Delete(maturityKey)
+	return nil
+}
+
+// GetPendingRecovery returns the pending recovery of the ID, or nil if there
+// is none.
+func (c *IDChainStore) GetPendingRecovery(ID string) (*PendingRecovery, error) {
+	key := []byte{byte(IX_PendingRecovery)}
+	return getPendingRecovery(c, append(key, ID...))
+}
+
+type getter interface {
+	Get(key []byte) ([]byte, error)
+}
+
+func getPendingRecovery(db getter, key []byte) (*PendingRecovery, error) {
+	data, err := db.Get(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	recovery := new(PendingRecovery)
+	if err := recovery.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	return recovery, nil
+}
+
+func serializeStrings(w io.Writer, strs []string) error {
+	if err := common.WriteVarUint(w, uint64(len(strs))); err != nil {
+		return err
+	}
+	for _, str := range strs {
+		if err := common.WriteVarString(w, str); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deserializeStrings(data []byte) ([]string, error) {
+	r := bytes.NewReader(data)
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, errors.New("[IDChainStore], string list deserialize failed.")
+	}
+
+	strs := make([]string, count)
+	for i := uint64(0); i < count; i++ {
+		if strs[i], err = common.ReadVarString(r); err != nil {
+			return nil, errors.New("[IDChainStore], string list deserialize failed.")
+		}
+	}
+
+	return strs, nil
+}
@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.SideChain/blockchain"
+)
+
+// reindexDeleteBatchSize is the number of index entries deleted per batch
+// while clearing the identification indexes.
+const reindexDeleteBatchSize = 10000
+
+// Stages of a rebuild of the identification indexes.
+const (
+	reindexClearing = 0x00
+	reindexIndexing = 0x01
+)
+
+// ErrReindexInterrupted is returned by Reindex when it is interrupted, the
+// rebuild resumes from where it stopped on the next call.
+var ErrReindexInterrupted = errors.New("reindex interrupted")
+
+// identificationIndexPrefixes are the prefixes of all the indexes written by
+// persistIdentificationIndexes.
+var identificationIndexPrefixes = []byte{
+	byte(blockchain.IX_Identification),
+	IX_ServiceEndpoint,
+	IX_IdentificationUndo,
+	IX_IdentificationController,
+	IX_RecoveryGuardians,
+	IX_PendingRecovery,
+	IX_RecoveryMaturity,
+	IX_Anchor,
+	IX_StateNode,
+	IX_StateRoot,
+	IX_IdentificationChanges,
+	IX_IdentificationStats,
+	IX_DailyRegistrations,
+	IX_IdentificationPathCount,
+	IX_AddressIdentification,
+	IX_IdentificationSequence,
+	IX_IdentificationHeight,
+	IX_IdentificationPath,
+}
+
+// ReindexInProgress returns whether a rebuild of the identification indexes
+// has been started and not finished.
+func (c *IDChainStore) ReindexInProgress() bool {
+	_, err := c.Get([]byte{byte(IX_ReindexProgress)})
+	return err == nil
+}
+
+// Reindex clears the identification indexes and rebuilds them from the
+// stored blocks, calling progress after every block. The progress is saved
+// with every block, so an interrupted rebuild resumes where it stopped.
+func (c *IDChainStore) Reindex(interrupt <-chan struct{}, progress func(height, bestHeight uint32)) error {
+	stage, next, err := c.reindexProgress()
+	if err != nil {
+		return err
+	}
+
+	if stage == reindexClearing {
+		if err := c.putReindexProgress(reindexClearing, 0); err != nil {
+			return err
+		}
+		if err := c.clearIdentificationIndexes(); err != nil {
+			return err
+		}
+		if err := c.putReindexProgress(reindexIndexing, 0); err != nil {
+			return err
+		}
+	}
+
+	bestHeight := c.GetHeight()
+	for height := next; height <= bestHeight; height++ {
+		select {
+		case <-interrupt:
+			return ErrReindexInterrupted
+		default:
+		}
+
+		hash, err := c.GetBlockHash(height)
+		if err != nil {
+			return err
+		}
+		block, err := c.GetBlock(hash)
+		if err != nil {
+			return err
+		}
+
+		batch := c.NewBatch()
+		if err := c.persistIdentificationIndexes(batch, block); err != nil {
+			return err
+		}
+		batch.Put([]byte{byte(IX_ReindexProgress)}, encodeReindexProgress(reindexIndexing, height+1))
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(height, bestHeight)
+		}
+	}
+
+	return c.Delete([]byte{byte(IX_ReindexProgress)})
+}
+
+// reindexProgress returns the stage of the unfinished rebuild and the height
+// of the next block to index, a rebuild not started yet is clearing.
+func (c *IDChainStore) reindexProgress() (byte, uint32, error) {
+	data, err := c.Get([]byte{byte(IX_ReindexProgress)})
+	if err != nil {
+		return reindexClearing, 0, nil
+	}
+	if len(data) != 5 {
+		return 0, 0, errors.New("[IDChainStore], invalid reindex progress.")
+	}
+	return data[0], binary.BigEndian.Uint32(data[1:]), nil
+}
+
+func (c *IDChainStore) putReindexProgress(stage byte, height uint32) error {
+	return c.Put([]byte{byte(IX_ReindexProgress)}, encodeReindexProgress(stage, height))
+}
+
+func encodeReindexProgress(stage byte, height uint32) []byte {
+	data := make([]byte, 5)
+	data[0] = stage
+	binary.BigEndian.PutUint32(data[1:], height)
+	return data
+}
+
+// clearIdentificationIndexes deletes every entry of the identification
+// indexes.
+func (c *IDChainStore) clearIdentificationIndexes() error {
+	for _, prefix := range identificationIndexPrefixes {
+		iter := c.NewIterator([]byte{prefix})
+		batch := c.NewBatch()
+		count := 0
+		for iter.Next() {
+			key := make([]byte, len(iter.Key()))
+			copy(key, iter.Key())
+			batch.Delete(key)
+
+			count++
+			if count%reindexDeleteBatchSize == 0 {
+				if err := batch.Commit(); err != nil {
+					iter.Release()
+					return err
+				}
+				batch = c.NewBatch()
+			}
+		}
+		iter.Release()
+
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
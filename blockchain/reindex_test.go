@@ -0,0 +1,70 @@
+package blockchain
+
+import "testing"
+
+func TestReindex(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	first := registerTx(ID, "kyc/person/identityCard")
+	saveTestBlock(t, store, first)
+	second := registerTx(other, "kyc/person/phone")
+	saveTestBlock(t, store, second)
+	last := registerTx(ID, "kyc/person/identityCard", "kyc/person/phone")
+	saveTestBlock(t, store, last)
+
+	bestHeight := store.GetHeight()
+	root, err := store.GetStateRoot(bestHeight)
+	if err != nil {
+		t.Fatal("state root error:", err)
+	}
+	stats, err := store.GetIdentificationStats()
+	if err != nil {
+		t.Fatal("stats error:", err)
+	}
+
+	// An interrupted rebuild resumes on the next call.
+	interrupt := make(chan struct{})
+	close(interrupt)
+	if err := store.Reindex(interrupt, nil); err != ErrReindexInterrupted {
+		t.Fatal("interrupted reindex error:", err)
+	}
+	if !store.ReindexInProgress() {
+		t.Error("interrupted reindex should be in progress!")
+	}
+
+	var heights []uint32
+	err = store.Reindex(nil, func(height, best uint32) {
+		if best != bestHeight {
+			t.Errorf("reindex best height %d, expected %d", best, bestHeight)
+		}
+		heights = append(heights, height)
+	})
+	if err != nil {
+		t.Fatal("reindex error:", err)
+	}
+	if store.ReindexInProgress() {
+		t.Error("finished reindex should not be in progress!")
+	}
+	if len(heights) != int(bestHeight)+1 {
+		t.Errorf("reindexed %d blocks, expected %d", len(heights), bestHeight+1)
+	}
+
+	if txHash, err := getRegisterTx(store, ID, "kyc/person/identityCard"); err != nil ||
+		!txHash.IsEqual(last.Hash()) {
+		t.Error("reindexed path should be registered by the last transaction!")
+	}
+	if txHash, err := getRegisterTx(store, other, "kyc/person/phone"); err != nil ||
+		!txHash.IsEqual(second.Hash()) {
+		t.Error("reindexed path of another ID error!")
+	}
+	if reindexed, err := store.GetStateRoot(bestHeight); err != nil || !reindexed.IsEqual(root) {
+		t.Error("reindexed state root differs!")
+	}
+	if reindexed, err := store.GetIdentificationStats(); err != nil || *reindexed != *stats {
+		t.Error("reindexed stats differ!")
+	}
+}
@@ -0,0 +1,92 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// GetIdentificationSequence returns the sequence number of the ID, the number
+// of transactions which have changed its service endpoints, its controller or
+// its recovery. The payloads of those transactions carry the sequence number
+// they apply to, so an old payload can not be replayed.
+func (c *IDChainStore) GetIdentificationSequence(ID string) (uint32, error) {
+	return getIdentificationSequence(c, ID)
+}
+
+func getIdentificationSequence(db getter, ID string) (uint32, error) {
+	key := []byte{byte(IX_IdentificationSequence)}
+	data, err := db.Get(append(key, ID...))
+	if err != nil {
+		return 0, nil
+	}
+
+	sequence, err := common.ReadUint32(bytes.NewReader(data))
+	if err != nil {
+		return 0, errors.New("[IDChainStore], sequence deserialize failed.")
+	}
+	return sequence, nil
+}
+
+// persistIdentificationSequence checks that a transaction of the block applies
+// to the current sequence number of the ID, and moves the ID to the next one.
+func (c *IDChainStore) persistIdentificationSequence(batch *indexBatch, ID string, sequence uint32) error {
+	current, err := getIdentificationSequence(batch, ID)
+	if err != nil {
+		return err
+	}
+	if sequence != current {
+		return errors.New("[IDChainStore], sequence " + strconv.FormatUint(uint64(sequence), 10) +
+			" of ID " + ID + " is not the current sequence " + strconv.FormatUint(uint64(current), 10))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := common.WriteUint32(buf, current+1); err != nil {
+		return err
+	}
+	key := []byte{byte(IX_IdentificationSequence)}
+	batch.Put(append(key, ID...), buf.Bytes())
+	return nil
+}
+
+// TransactionSequence returns the ID and the sequence number the transaction
+// applies to, if any.
+func TransactionSequence(txn *types.Transaction) (string, uint32, bool) {
+	switch pld := txn.Payload.(type) {
+	case *id.PayloadRegisterServiceEndpoint:
+		return pld.ID, pld.Sequence, true
+	case *id.PayloadTransferIdentification:
+		return pld.ID, pld.Sequence, true
+	case *id.PayloadSetRecoveryGuardians:
+		return pld.ID, pld.Sequence, true
+	case *id.PayloadRecoverIdentification:
+		return pld.ID, pld.Sequence, true
+	case *id.PayloadCancelRecovery:
+		return pld.ID, pld.Sequence, true
+	}
+	return "", 0, false
+}
+
+// checkSequenceConflicts checks that no two transactions of the block apply
+// to the sequence number of the same ID. The context of every transaction is
+// checked against the state before the block, which the first of them
+// changes.
+func checkSequenceConflicts(b *types.Block) error {
+	ids := make(map[string]struct{})
+	for _, txn := range b.Transactions {
+		ID, _, ok := TransactionSequence(txn)
+		if !ok {
+			continue
+		}
+		if _, ok := ids[ID]; ok {
+			return errors.New("[IDChainStore], more than one transaction of ID " + ID + " in the block")
+		}
+		ids[ID] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestIdentificationSequence(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	store := make(memNodeStore)
+	if sequence, err := getIdentificationSequence(store, ID); err != nil || sequence != 0 {
+		t.Error("sequence of a new ID error!")
+	}
+
+	buf := new(bytes.Buffer)
+	common.WriteUint32(buf, 5)
+	store.Put(append([]byte{IX_IdentificationSequence}, ID...), buf.Bytes())
+	if sequence, err := getIdentificationSequence(store, ID); err != nil || sequence != 5 {
+		t.Error("sequence of an ID error!")
+	}
+}
+
+func TestCheckSequenceConflicts(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	guardians := &types.Transaction{TxType: id.SetRecoveryGuardians,
+		Payload: &id.PayloadSetRecoveryGuardians{ID: ID}}
+	recovery := &types.Transaction{TxType: id.RecoverIdentification,
+		Payload: &id.PayloadRecoverIdentification{ID: ID, NewController: other}}
+	transfer := &types.Transaction{TxType: id.TransferIdentification,
+		Payload: &id.PayloadTransferIdentification{ID: other, NewController: ID}}
+	register := &types.Transaction{TxType: id.RegisterIdentification,
+		Payload: &id.PayloadRegisterIdentification{ID: ID}}
+
+	block := &types.Block{Transactions: []*types.Transaction{guardians, transfer, register}}
+	if err := checkSequenceConflicts(block); err != nil {
+		t.Error("block with one transaction per ID error:", err)
+	}
+
+	// Setting the guardians and recovering with them in the same block.
+	block = &types.Block{Transactions: []*types.Transaction{guardians, recovery}}
+	if err := checkSequenceConflicts(block); err == nil {
+		t.Error("block with two transactions of an ID should fail!")
+	}
+}
@@ -0,0 +1,327 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// The identification state tree is a sparse merkle tree over the hashes of
+// ID+path, whose leaves are the hashes of the latest registered content of
+// the paths. A subtree holding a single leaf is replaced by the leaf itself,
+// and an empty subtree hashes to EmptyStateRoot.
+const stateKeyBits = 256
+
+const (
+	stateLeafNode     = 0x00
+	stateInternalNode = 0x01
+	stateNodeSize     = 65
+)
+
+// EmptyStateRoot is the root of the state tree without any leaf.
+var EmptyStateRoot = common.Uint256{}
+
+// StateKey returns the key of the path of an ID in the state tree.
+func StateKey(ID, path string) common.Uint256 {
+	return common.Uint256(sha256.Sum256([]byte(ID + path)))
+}
+
+// StateLeaf is a leaf of the state tree.
+type StateLeaf struct {
+	Key   common.Uint256
+	Value common.Uint256
+}
+
+// StateProof proves the value of a key in the state tree, or that the key is
+// not in the tree. Siblings are the hashes of the sibling subtrees along the
+// path of the key from the root down, and Leaf is the leaf the path ends at,
+// nil if it ends at an empty subtree.
+type StateProof struct {
+	Siblings []common.Uint256
+	Leaf     *StateLeaf
+}
+
+// Verify checks the proof against the root, and returns the value of the key
+// or nil if the key is not in the tree.
+func (p *StateProof) Verify(root common.Uint256, key common.Uint256) (*common.Uint256, error) {
+	if len(p.Siblings) > stateKeyBits {
+		return nil, errors.New("state proof is too long")
+	}
+
+	var hash common.Uint256
+	var value *common.Uint256
+	if p.Leaf != nil {
+		hash = stateLeafHash(p.Leaf.Key, p.Leaf.Value)
+		if p.Leaf.Key.IsEqual(key) {
+			value = &p.Leaf.Value
+		} else {
+			// A leaf of another key only proves the key is not in the tree
+			// if the key leads to the same subtree.
+			for depth := range p.Siblings {
+				if stateKeyBit(p.Leaf.Key, depth) != stateKeyBit(key, depth) {
+					return nil, errors.New("state proof leaf is off the key path")
+				}
+			}
+		}
+	}
+
+	for depth := len(p.Siblings) - 1; depth >= 0; depth-- {
+		if stateKeyBit(key, depth) == 0 {
+			hash = stateInternalHash(hash, p.Siblings[depth])
+		} else {
+			hash = stateInternalHash(p.Siblings[depth], hash)
+		}
+	}
+
+	if !hash.IsEqual(root) {
+		return nil, errors.New("state proof does not match the root")
+	}
+	return value, nil
+}
+
+// stateNodeStore is where the nodes of the state tree are kept.
+type stateNodeStore interface {
+	getter
+	Put(key []byte, value []byte)
+}
+
+type stateNode struct {
+	leaf  bool
+	left  common.Uint256
+	right common.Uint256
+}
+
+// stateTree updates the state tree of a block. Nodes are stored by their hashes, so the nodes of earlier
+// roots stay in the store and can still be proven.
+type stateTree struct {
+	store stateNodeStore
+	root  common.Uint256
+}
+
+// Root returns the current root of the tree.
+func (t *stateTree) Root() common.Uint256 {
+	return t.root
+}
+
+// Update sets the value of the key.
+func (t *stateTree) Update(key common.Uint256, value common.Uint256) error {
+	root, err := t.update(t.root, key, value, 0)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+func (t *stateTree) update(hash common.Uint256, key common.Uint256, value common.Uint256,
+	depth int) (common.Uint256, error) {
+	if hash.IsEqual(EmptyStateRoot) {
+		return t.putNode(stateLeafNode, key, value), nil
+	}
+
+	node, err := getStateNode(t.store, hash)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+
+	if node.leaf {
+		if node.left.IsEqual(key) {
+			return t.putNode(stateLeafNode, key, value), nil
+		}
+		leaf := t.putNode(stateLeafNode, key, value)
+		return t.split(depth, node.left, hash, key, leaf), nil
+	}
+
+	if stateKeyBit(key, depth) == 0 {
+		left, err := t.update(node.left, key, value, depth+1)
+		if err != nil {
+			return common.Uint256{}, err
+		}
+		return t.putNode(stateInternalNode, left, node.right), nil
+	}
+	right, err := t.update(node.right, key, value, depth+1)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	return t.putNode(stateInternalNode, node.left, right), nil
+}
+
+// split returns the subtree at the depth holding the two leaves.
+func (t *stateTree) split(depth int, keyA common.Uint256, leafA common.Uint256,
+	keyB common.Uint256, leafB common.Uint256) common.Uint256 {
+	bitA, bitB := stateKeyBit(keyA, depth), stateKeyBit(keyB, depth)
+	if bitA == bitB {
+		child := t.split(depth+1, keyA, leafA, keyB, leafB)
+		if bitA == 0 {
+			return t.putNode(stateInternalNode, child, EmptyStateRoot)
+		}
+		return t.putNode(stateInternalNode, EmptyStateRoot, child)
+	}
+
+	if bitA == 0 {
+		return t.putNode(stateInternalNode, leafA, leafB)
+	}
+	return t.putNode(stateInternalNode, leafB, leafA)
+}
+
+// proveState returns the proof of the key under the root.
+func proveState(db getter, root common.Uint256, key common.Uint256) (*StateProof, error) {
+	proof := new(StateProof)
+	hash := root
+	for depth := 0; !hash.IsEqual(EmptyStateRoot); depth++ {
+		node, err := getStateNode(db, hash)
+		if err != nil {
+			return nil, err
+		}
+		if node.leaf {
+			proof.Leaf = &StateLeaf{Key: node.left, Value: node.right}
+			break
+		}
+
+		if stateKeyBit(key, depth) == 0 {
+			proof.Siblings = append(proof.Siblings, node.right)
+			hash = node.left
+		} else {
+			proof.Siblings = append(proof.Siblings, node.left)
+			hash = node.right
+		}
+	}
+
+	return proof, nil
+}
+
+// putNode stores a node and returns its hash. A leaf node holds its key and
+// value, an internal node the hashes of its children.
+func (t *stateTree) putNode(nodeType byte, left common.Uint256, right common.Uint256) common.Uint256 {
+	var hash common.Uint256
+	if nodeType == stateLeafNode {
+		hash = stateLeafHash(left, right)
+	} else {
+		hash = stateInternalHash(left, right)
+	}
+
+	data := make([]byte, 0, stateNodeSize)
+	data = append(data, nodeType)
+	data = append(data, left[:]...)
+	data = append(data, right[:]...)
+	t.store.Put(stateNodeKey(hash), data)
+
+	return hash
+}
+
+func getStateNode(db getter, hash common.Uint256) (*stateNode, error) {
+	data, err := db.Get(stateNodeKey(hash))
+	if err != nil {
+		return nil, errors.New("[IDChainStore], state node not found.")
+	}
+	if len(data) != stateNodeSize {
+		return nil, errors.New("[IDChainStore], invalid state node.")
+	}
+
+	node := &stateNode{leaf: data[0] == stateLeafNode}
+	copy(node.left[:], data[1:33])
+	copy(node.right[:], data[33:])
+	return node, nil
+}
+
+func stateNodeKey(hash common.Uint256) []byte {
+	key := []byte{byte(IX_StateNode)}
+	return append(key, hash[:]...)
+}
+
+func stateLeafHash(key common.Uint256, value common.Uint256) common.Uint256 {
+	return stateHash(stateLeafNode, key, value)
+}
+
+func stateInternalHash(left common.Uint256, right common.Uint256) common.Uint256 {
+	return stateHash(stateInternalNode, left, right)
+}
+
+func stateHash(nodeType byte, a common.Uint256, b common.Uint256) common.Uint256 {
+	data := make([]byte, 0, stateNodeSize)
+	data = append(data, nodeType)
+	data = append(data, a[:]...)
+	data = append(data, b[:]...)
+	return common.Uint256(sha256.Sum256(data))
+}
+
+// stateKeyBit returns the bit of the key at the depth, from the most
+// significant bit of the first byte.
+func stateKeyBit(key common.Uint256, depth int) byte {
+	return (key[depth/8] >> uint(7-depth%8)) & 1
+}
+
+// newStateTree returns the state tree of the block at the height, starting
+// from the root of the previous block. It returns nil if the previous block
+// has no root: the blocks indexed before the state tree was introduced are
+// not in the tree, so no root is built until a reindex rebuilds the tree
+// from the genesis block. The genesis block is stored before the indexes
+// are hooked in and registers no ID, so its root is the empty root.
+func (c *IDChainStore) newStateTree(batch *indexBatch, height uint32) (*stateTree, error) {
+	if height <= 1 {
+		return &stateTree{store: batch, root: EmptyStateRoot}, nil
+	}
+
+	data, err := batch.Get(heightKey(IX_StateRoot, height-1))
+	if err != nil {
+		return nil, nil
+	}
+	root, err := common.Uint256FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return &stateTree{store: batch, root: *root}, nil
+}
+
+// persistStateRoot saves the root of the state tree after the block.
+func (c *IDChainStore) persistStateRoot(batch *indexBatch, tree *stateTree) {
+	if tree == nil {
+		return
+	}
+	root := tree.Root()
+	batch.Put(heightKey(IX_StateRoot, batch.height), root.Bytes())
+}
+
+// HasStateTree returns whether the state tree covers the chain from the
+// genesis block, it does not on nodes indexed before the state tree was
+// introduced until they reindex.
+func (c *IDChainStore) HasStateTree() bool {
+	height := c.GetHeight()
+	if height == 0 {
+		return true
+	}
+	_, err := c.Get(heightKey(IX_StateRoot, height))
+	return err == nil
+}
+
+// GetStateRoot returns the root of the identification state tree after the
+// block at the height.
+func (c *IDChainStore) GetStateRoot(height uint32) (common.Uint256, error) {
+	data, err := c.Get(heightKey(IX_StateRoot, height))
+	if err != nil {
+		if !c.HasStateTree() {
+			return common.Uint256{}, errors.New("[IDChainStore], state tree not built, reindex to build it.")
+		}
+		return common.Uint256{}, errors.New("[IDChainStore], state root not found.")
+	}
+	root, err := common.Uint256FromBytes(data)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	return *root, nil
+}
+
+// GetStateProof returns the state root after the block at the height, and
+// the proof of the key under it.
+func (c *IDChainStore) GetStateProof(height uint32, key common.Uint256) (common.Uint256, *StateProof, error) {
+	root, err := c.GetStateRoot(height)
+	if err != nil {
+		return common.Uint256{}, nil, err
+	}
+	proof, err := proveState(c, root, key)
+	if err != nil {
+		return common.Uint256{}, nil, err
+	}
+	return root, proof, nil
+}
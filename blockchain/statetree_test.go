@@ -0,0 +1,95 @@
+package blockchain
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+type memNodeStore map[string][]byte
+
+func (s memNodeStore) Get(key []byte) ([]byte, error) {
+	value, ok := s[string(key)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return value, nil
+}
+
+func (s memNodeStore) Put(key []byte, value []byte) {
+	s[string(key)] = value
+}
+
+func TestStateTree(t *testing.T) {
+	store := make(memNodeStore)
+	tree := &stateTree{store: store, root: EmptyStateRoot}
+
+	absent := StateKey("ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6", "kyc/person/phone")
+	proof, err := proveState(store, tree.Root(), absent)
+	if err != nil {
+		t.Fatal("prove in empty tree error:", err)
+	}
+	if value, err := proof.Verify(tree.Root(), absent); err != nil || value != nil {
+		t.Error("empty tree proof error!")
+	}
+
+	values := make(map[common.Uint256]common.Uint256)
+	for i := 0; i < 64; i++ {
+		key := StateKey("ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6", "path/"+strconv.Itoa(i))
+		values[key] = common.Uint256{byte(i), 1}
+		if err := tree.Update(key, values[key]); err != nil {
+			t.Fatal("update error:", err)
+		}
+	}
+	oldRoot := tree.Root()
+
+	// Overwrite a value, the old root must still prove the old value.
+	updated := StateKey("ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6", "path/7")
+	if err := tree.Update(updated, common.Uint256{7, 2}); err != nil {
+		t.Fatal("update error:", err)
+	}
+
+	for key, expected := range values {
+		if key == updated {
+			expected = common.Uint256{7, 2}
+		}
+		proof, err := proveState(store, tree.Root(), key)
+		if err != nil {
+			t.Fatal("prove error:", err)
+		}
+		value, err := proof.Verify(tree.Root(), key)
+		if err != nil || value == nil || *value != expected {
+			t.Error("membership proof error!")
+		}
+	}
+
+	proof, err = proveState(store, oldRoot, updated)
+	if err != nil {
+		t.Fatal("prove error:", err)
+	}
+	if value, err := proof.Verify(oldRoot, updated); err != nil || *value != (common.Uint256{7, 1}) {
+		t.Error("old root proof error!")
+	}
+
+	proof, err = proveState(store, tree.Root(), absent)
+	if err != nil {
+		t.Fatal("prove error:", err)
+	}
+	if value, err := proof.Verify(tree.Root(), absent); err != nil || value != nil {
+		t.Error("non-membership proof error!")
+	}
+	if _, err := proof.Verify(oldRoot, absent); err == nil {
+		t.Error("proof verified against a wrong root!")
+	}
+
+	// A proof of another key must not verify for the key.
+	proof, err = proveState(store, tree.Root(), updated)
+	if err != nil {
+		t.Fatal("prove error:", err)
+	}
+	if _, err := proof.Verify(tree.Root(), absent); err == nil {
+		t.Error("proof of another key verified!")
+	}
+}
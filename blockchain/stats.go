@@ -0,0 +1,164 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.SideChain/blockchain"
+)
+
+// secondsPerDay is the length of the days registrations are counted by.
+const secondsPerDay = 24 * 60 * 60
+
+// IdentificationStats are the counters of the identification registry.
+// Registrations counts the registered paths, registering a path again counts
+// again, and Revocations the paths registered again without any value. There
+// is no revoke operation: the latest registration of a path replaces its
+// content, so registering a path without any value is how its values are
+// revoked. A first registration without any value revokes nothing.
+type IdentificationStats struct {
+	TotalIDs      uint64
+	TotalPaths    uint64
+	Registrations uint64
+	Revocations   uint64
+}
+
+// DailyRegistrations is the number of paths registered in the blocks of a
+// UTC day, Day is the number of days since the unix epoch.
+type DailyRegistrations struct {
+	Day           uint32
+	Registrations uint64
+}
+
+func (s *IdentificationStats) bytes() []byte {
+	data := make([]byte, 32)
+	binary.BigEndian.PutUint64(data[0:], s.TotalIDs)
+	binary.BigEndian.PutUint64(data[8:], s.TotalPaths)
+	binary.BigEndian.PutUint64(data[16:], s.Registrations)
+	binary.BigEndian.PutUint64(data[24:], s.Revocations)
+	return data
+}
+
+func (s *IdentificationStats) add(o *IdentificationStats) {
+	s.TotalIDs += o.TotalIDs
+	s.TotalPaths += o.TotalPaths
+	s.Registrations += o.Registrations
+	s.Revocations += o.Revocations
+}
+
+func identificationStatsFromBytes(data []byte) (*IdentificationStats, error) {
+	if len(data) != 32 {
+		return nil, errors.New("[IDChainStore], invalid identification stats.")
+	}
+	return &IdentificationStats{
+		TotalIDs:      binary.BigEndian.Uint64(data[0:]),
+		TotalPaths:    binary.BigEndian.Uint64(data[8:]),
+		Registrations: binary.BigEndian.Uint64(data[16:]),
+		Revocations:   binary.BigEndian.Uint64(data[24:]),
+	}, nil
+}
+
+// countRegistration counts the registration of a path of an ID in the stats
+// of the block, it must be called before the path is indexed.
+func (c *IDChainStore) countRegistration(batch *indexBatch, ID string, path string, values int) error {
+	batch.stats.Registrations++
+
+	key := []byte{byte(blockchain.IX_Identification)}
+	key = append(key, ID...)
+	key = append(key, path...)
+	if _, err := batch.Get(key); err == nil {
+		if values == 0 {
+			batch.stats.Revocations++
+		}
+		return nil
+	}
+	batch.stats.TotalPaths++
+
+	// The number of paths of every ID tells whether the ID is new.
+	countKey := []byte{byte(IX_IdentificationPathCount)}
+	countKey = append(countKey, ID...)
+	var count uint32
+	if data, err := batch.Get(countKey); err == nil {
+		if len(data) != 4 {
+			return errors.New("[IDChainStore], invalid path count.")
+		}
+		count = binary.BigEndian.Uint32(data)
+	}
+	if count == 0 {
+		batch.stats.TotalIDs++
+	}
+
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, count+1)
+	batch.Put(countKey, data)
+	return nil
+}
+
+// persistIdentificationStats adds the stats of the block to the counters.
+func (c *IDChainStore) persistIdentificationStats(batch *indexBatch, timestamp uint32) error {
+	if batch.stats.Registrations == 0 {
+		return nil
+	}
+
+	stats := new(IdentificationStats)
+	key := []byte{byte(IX_IdentificationStats)}
+	if data, err := batch.Get(key); err == nil {
+		if stats, err = identificationStatsFromBytes(data); err != nil {
+			return err
+		}
+	}
+	stats.add(&batch.stats)
+	batch.Put(key, stats.bytes())
+
+	dayKey := heightKey(IX_DailyRegistrations, DayOf(timestamp))
+	var registrations uint64
+	if data, err := batch.Get(dayKey); err == nil && len(data) == 8 {
+		registrations = binary.BigEndian.Uint64(data)
+	}
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, registrations+batch.stats.Registrations)
+	batch.Put(dayKey, data)
+
+	return nil
+}
+
+// GetIdentificationStats returns the counters of the identification
+// registry.
+func (c *IDChainStore) GetIdentificationStats() (*IdentificationStats, error) {
+	data, err := c.Get([]byte{byte(IX_IdentificationStats)})
+	if err != nil {
+		return new(IdentificationStats), nil
+	}
+	return identificationStatsFromBytes(data)
+}
+
+// GetDailyRegistrations returns the number of registrations of the days from
+// start to end with any registration, in day order.
+func (c *IDChainStore) GetDailyRegistrations(start, end uint32) ([]DailyRegistrations, error) {
+	iter := c.NewIterator([]byte{byte(IX_DailyRegistrations)})
+	defer iter.Release()
+
+	var days []DailyRegistrations
+	for ok := iter.Seek(heightKey(IX_DailyRegistrations, start)); ok; ok = iter.Next() {
+		key, value := iter.Key(), iter.Value()
+		if len(key) != 5 || len(value) != 8 {
+			return nil, errors.New("[IDChainStore], invalid daily registrations.")
+		}
+		day := binary.BigEndian.Uint32(key[1:])
+		if day > end {
+			break
+		}
+		days = append(days, DailyRegistrations{
+			Day:           day,
+			Registrations: binary.BigEndian.Uint64(value),
+		})
+	}
+
+	return days, nil
+}
+
+// DayOf returns the day of a block timestamp, as counted by the daily
+// registrations.
+func DayOf(timestamp uint32) uint32 {
+	return timestamp / secondsPerDay
+}
@@ -0,0 +1,66 @@
+package blockchain
+
+import (
+	"math"
+	"testing"
+
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+)
+
+func TestIdentificationStats(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	store, remove := newTestChainStore(t)
+	defer remove()
+
+	// A first registration without any value revokes nothing.
+	empty := registerTx(other, "kyc/person/phone")
+	empty.Payload.(*id.PayloadRegisterIdentification).Contents[0].Values = nil
+	saveTestBlock(t, store, registerTx(ID, "kyc/person/identityCard", "kyc/person/phone"), empty)
+	saveTestBlock(t, store, registerTx(ID, "kyc/person/identityCard"))
+
+	stats, err := store.GetIdentificationStats()
+	if err != nil {
+		t.Fatal("stats error:", err)
+	}
+	expected := IdentificationStats{TotalIDs: 2, TotalPaths: 3, Registrations: 4}
+	if *stats != expected {
+		t.Errorf("stats %+v, expected %+v", *stats, expected)
+	}
+
+	// Registering a path again without any value revokes it.
+	revoke := registerTx(ID, "kyc/person/phone")
+	revoke.Payload.(*id.PayloadRegisterIdentification).Contents[0].Values = nil
+	block := saveTestBlock(t, store, revoke)
+	stats, _ = store.GetIdentificationStats()
+	expected = IdentificationStats{TotalIDs: 2, TotalPaths: 3, Registrations: 5, Revocations: 1}
+	if *stats != expected {
+		t.Errorf("stats after a revocation %+v, expected %+v", *stats, expected)
+	}
+
+	days, err := store.GetDailyRegistrations(0, math.MaxUint32)
+	if err != nil {
+		t.Fatal("daily registrations error:", err)
+	}
+	var registrations uint64
+	for _, day := range days {
+		registrations += day.Registrations
+	}
+	if registrations != stats.Registrations {
+		t.Errorf("daily registrations sum to %d, expected %d", registrations, stats.Registrations)
+	}
+	if len(days) == 0 || days[len(days)-1].Day != DayOf(block.Header.Timestamp) {
+		t.Error("last day of the registrations error!")
+	}
+
+	// A rolled back block takes its counts with it.
+	if err := store.RollbackBlock(block.Hash()); err != nil {
+		t.Fatal("rollback error:", err)
+	}
+	stats, _ = store.GetIdentificationStats()
+	expected = IdentificationStats{TotalIDs: 2, TotalPaths: 3, Registrations: 4}
+	if *stats != expected {
+		t.Errorf("stats after the rollback %+v, expected %+v", *stats, expected)
+	}
+}
@@ -1,33 +1,61 @@
 package main
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
 
+	chaincfg "github.com/elastos/Elastos.ELA.SideChain/config"
+	"github.com/elastos/Elastos.ELA.SideChain/types"
 	"github.com/elastos/Elastos.ELA.Utility/common"
 	"github.com/elastos/Elastos.ELA.Utility/elalog"
 )
 
 const (
-	ConfigFilename        = "./config.json"
-	defaultLogLevel       = "info"
-	defaultLogsFolderSize = 2 * elalog.GBSize  // 2 GB
-	defaultMaxLogFileSize = 20 * elalog.MBSize // 20 MB
-	defaultLogDir         = "logs"
+	ConfigFilename  = "./config.json"
+	defaultDataPath = "elastos_did"
+	defaultLogLevel = "info"
+	defaultLogDir   = "logs"
+
+	// envPrefix prefixes the names of the environment variables setting the
+	// flags.
+	envPrefix = "DID_"
+)
+
+// Command line flags, every flag can also be set by the environment variable
+// named envPrefix followed by the flag name in upper case. A flag given on
+// the command line wins over its environment variable.
+var (
+	configFile = flag.String("conf", ConfigFilename, "path of the config file")
+	dataDir    = flag.String("datadir", defaultDataPath, "directory of the chain, spv and log data")
+	logDir     = flag.String("logdir", "", "directory of the log files, default the logs directory in the data directory")
+	network    = flag.String("network", "", "network to join, MainNet, TestNet or RegNet, default the NetType of the config file")
+	rpcPort    = flag.Uint("rpcport", 0, "port of the JSON-RPC server")
+	restPort   = flag.Uint("restport", 0, "port of the RESTful server")
+	wsPort     = flag.Uint("wsport", 0, "port of the websocket server")
+	mining     = flag.Bool("mining", false, "mine blocks")
+	payToAddr  = flag.String("paytoaddr", "", "address the rewards of the mined blocks are paid to")
+	reindex    = flag.Bool("reindex", false, "rebuild the identification indexes from the stored blocks")
 )
 
 var (
 	// Set default active net params.
 	activeNetParams = &params.MainNetParams
 
-	// Load configuration from file.
-	cfg, loadConfigErr = loadNewConfig()
+	// cfg is the configuration of the node, loaded at startup.
+	cfg *appConfig
 )
 
 type config struct {
@@ -43,13 +71,34 @@ type config struct {
 		HttpJsonPort               uint16
 		HttpWsPort                 uint16
 		NodePort                   uint16
-		PrintLevel                 elalog.Level
+		PrintLevel                 *elalog.Level
+		LogLevels                  map[string]elalog.Level
 		MaxLogsSize                int64
 		MaxPerLogSize              int64
 		FoundationAddress          string
 		DisableTxFilters           bool
 		MainChainFoundationAddress string
-		PowConfiguration           struct {
+		GenesisBlock               string
+		Premine                    []struct {
+			Address string
+			Amount  int64
+		}
+		Checkpoints []struct {
+			Height uint32
+			Hash   string
+		}
+		DisableCheckpoints     bool
+		MonitorState           *bool
+		RpcAllowedActions      []string
+		CheckpointScriptChecks bool
+		LogRotation            string
+		CompressLogs           bool
+		MaxLogAge              int
+		LogFormat              struct {
+			Stdout string
+			File   string
+		}
+		PowConfiguration struct {
 			PayToAddr    string
 			AutoMining   bool
 			MinerInfo    string
@@ -67,54 +116,213 @@ type appConfig struct {
 	MinerInfo         string
 	MinerAddr         string
 	LogLevel          string
+	LogLevels         map[string]elalog.Level
 	MaxLogsFolderSize int64
 	MaxPerLogFileSize int64
 	MonitorState      bool
+	LogDir            string
+	StdoutLogFormat   string
+	FileLogFormat     string
+
+	// LogRotation is the period a log file is written for, empty if only
+	// the size rotates the files, CompressLogs whether the rotated files
+	// are compressed, and MaxLogAge how long they are kept.
+	LogRotation  string
+	CompressLogs bool
+	MaxLogAge    time.Duration
+
+	// Checkpoints are the checkpoints enforced by the chain store, and
+	// SkipCheckpointScripts whether the scripts of the transactions up to
+	// the last one are skipped.
+	Checkpoints           params.Checkpoints
+	SkipCheckpointScripts bool
+
+	// RpcAllowedActions are the JSON-RPC actions the node serves, all of
+	// them when empty.
+	RpcAllowedActions []string
 }
 
+// networks are the chain parameters of the networks a node can join, by
+// the NetType naming them in the config file.
+var networks = map[string]*chaincfg.Params{
+	"MainNet": &params.MainNetParams,
+	"TestNet": &params.TestNetParams,
+	"RegNet":  &params.RegNetParams,
+}
+
+// givenFlags are the names of the flags given on the command line or by the
+// environment, parsed once at startup.
+var givenFlags map[string]bool
+
+// loadNewConfig parses the flags and resolves the configuration of the node
+// at startup. The defaults are returned along with an error, for the logs to
+// be written.
 func loadNewConfig() (*appConfig, error) {
-	appCfg := appConfig{
-		LogLevel:          defaultLogLevel,
-		MaxLogsFolderSize: defaultLogsFolderSize,
-		MaxPerLogFileSize: defaultMaxLogFileSize,
-		HttpRestPort:      20604,
-		HttpJsonPort:      20606,
-		HttpWsPort:        20605,
-		MinerAddr:         "8VYXVxKKSAxkmRrfmGpQR2Kc66XhG6m3ta",
-		MonitorState:      true,
-	}
-
-	data, err := ioutil.ReadFile(ConfigFilename)
+	appCfg := defaultAppConfig("MainNet")
+	appCfg.LogDir = filepath.Join(defaultDataPath, defaultLogDir)
+
+	var errs []string
+	givenFlags, errs = parseFlags()
+	if len(errs) > 0 {
+		return appCfg, configError(errs)
+	}
+	DataPath = *dataDir
+
+	resolved, chainParams, err := resolveConfig()
+	if err != nil {
+		return appCfg, err
+	}
+	activeNetParams = chainParams
+	return resolved, nil
+}
+
+// resolveConfig resolves the configuration in layers: the defaults of the
+// network, then the values set in the config file, then the flags. A layer
+// only overrides the values it sets. Every value is validated once all the
+// layers are applied, and all the problems found are reported together. It
+// does not change the running node, so the config file can be reloaded.
+func resolveConfig() (*appConfig, *chaincfg.Params, error) {
+	fileCfg, err := readConfigFile(*configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	netType := fileCfg.NetType
+	if givenFlags["network"] {
+		netType = *network
+	}
+	if netType == "" {
+		netType = "MainNet"
+	}
+	netParams, ok := networks[netType]
+	if !ok {
+		return nil, nil, errors.New("invalid NetType: should be MainNet, TestNet, RegNet")
+	}
+	chainParams := *netParams
+	appCfg := defaultAppConfig(netType)
+	appCfg.Checkpoints = params.GetCheckpoints(netParams)
+
+	var errs []string
+	errs = append(errs, applyConfigFile(netType, appCfg, &chainParams, fileCfg)...)
+	errs = append(errs, applyFlags(appCfg, givenFlags)...)
+	errs = append(errs, validateConfig(netType, appCfg, &chainParams)...)
+	if len(errs) > 0 {
+		return nil, nil, configError(errs)
+	}
+
+	return appCfg, &chainParams, nil
+}
+
+func configError(errs []string) error {
+	return errors.New("invalid configuration:\n\t" + strings.Join(errs, "\n\t"))
+}
+
+// parseFlags parses the command line, then sets the flags missing from it
+// from the environment. It returns the names of the flags set either way.
+func parseFlags() (map[string]bool, []string) {
+	flag.Usage = usage
+	return parseFlagSet(flag.CommandLine, os.Args[1:])
+}
+
+func parseFlagSet(flags *flag.FlagSet, args []string) (map[string]bool, []string) {
+	flags.Parse(args)
+
+	setFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	var errs []string
+	flags.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok || setFlags[f.Name] {
+			return
+		}
+		if err := flags.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid %s %q: %s", envName(f.Name), value, err))
+			return
+		}
+		setFlags[f.Name] = true
+	})
+
+	return setFlags, errs
+}
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(flagName)
+}
+
+// usage prints the help of the flags, with their environment variables.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "Options override the config file, every option can also be set by\n")
+	fmt.Fprintf(os.Stderr, "the environment variable in parentheses.\n\n")
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(os.Stderr, "  -%s (%s)\n    \t%s", f.Name, envName(f.Name), f.Usage)
+		if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" {
+			fmt.Fprintf(os.Stderr, " (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(os.Stderr)
+	})
+}
+
+// readConfigFile reads the config file, a missing file leaves every value to
+// the defaults.
+func readConfigFile(path string) (*config, error) {
+	cfg := new(config)
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		if _, ok := err.(*os.PathError); ok {
-			fmt.Println("WARNING: can't find config.json. Use default configurations in codes")
-			// if we can't find config.json, use default main net config.
-			return &appCfg, nil
-		} else {
-			return nil, errors.New("read config file error:" + err.Error())
+			fmt.Printf("WARNING: can't find %s. Use default configurations in codes\n", path)
+			return cfg, nil
 		}
+		return nil, errors.New("read config file error:" + err.Error())
 	}
 
 	// Map Application Options.
-	cfg := new(config)
-	err = json.Unmarshal(data, cfg)
-	if err != nil {
+	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, errors.New("config file json unmarshal error:" + err.Error())
 	}
-	if cfg.NetType == "" || cfg.NetType == "MainNet" {
-		//do nothing. default is main net
-	} else if cfg.NetType == "TestNet" {
-		activeNetParams = &params.TestNetParams
+	return cfg, nil
+}
+
+// defaultAppConfig returns the application defaults of the network.
+func defaultAppConfig(netType string) *appConfig {
+	appCfg := &appConfig{
+		LogLevel:     defaultLogLevel,
+		HttpRestPort: 20604,
+		HttpJsonPort: 20606,
+		HttpWsPort:   20605,
+		MinerAddr:    "8VYXVxKKSAxkmRrfmGpQR2Kc66XhG6m3ta",
+		MonitorState: true,
+
+		StdoutLogFormat: logFormatText,
+		FileLogFormat:   logFormatText,
+
+		SkipCheckpointScripts: true,
+	}
+	if netType == "TestNet" {
 		appCfg.HttpJsonPort = 21606
 		appCfg.HttpRestPort = 21604
 		appCfg.HttpWsPort = 20605
 		appCfg.MinerAddr = "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3"
-	} else {
-		return nil, errors.New("invalid NetType: should be MainNet, TestNet")
 	}
+	if netType == "RegNet" {
+		appCfg.HttpJsonPort = 22606
+		appCfg.HttpRestPort = 22604
+		appCfg.HttpWsPort = 22605
+		appCfg.MinerAddr = "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3"
+	}
+	return appCfg
+}
 
+// applyConfigFile overrides the values set in the config file, and returns
+// the problems with the values that can not be applied.
+func applyConfigFile(netType string, appCfg *appConfig, chainParams *chaincfg.Params, cfg *config) []string {
+	var errs []string
 	config := cfg.Configuration
 	powCfg := cfg.Configuration.PowConfiguration
+
 	if config.HttpRestPort > 0 {
 		appCfg.HttpRestPort = config.HttpRestPort
 	}
@@ -127,70 +335,360 @@ func loadNewConfig() (*appConfig, error) {
 	if powCfg.PayToAddr != "" {
 		appCfg.MinerAddr = powCfg.PayToAddr
 	}
-	appCfg.Mining = powCfg.AutoMining
-	appCfg.MinerInfo = powCfg.MinerInfo
-
-	appCfg.LogLevel = elalog.Level(config.PrintLevel).String()
+	if powCfg.AutoMining {
+		appCfg.Mining = true
+	}
+	if powCfg.MinerInfo != "" {
+		appCfg.MinerInfo = powCfg.MinerInfo
+	}
+	if config.MonitorState != nil {
+		appCfg.MonitorState = *config.MonitorState
+	}
+	appCfg.RpcAllowedActions = config.RpcAllowedActions
 
-	appCfg.MaxLogsFolderSize = config.MaxLogsSize
-	appCfg.MaxPerLogFileSize = config.MaxPerLogSize
-	appCfg.MonitorState = true
+	if config.PrintLevel != nil {
+		level := *config.PrintLevel
+		if !isLogLevel(level) {
+			errs = append(errs, fmt.Sprintf("PrintLevel %d is not a log level", level))
+		} else {
+			appCfg.LogLevel = level.String()
+		}
+	}
+	tags := make([]string, 0, len(config.LogLevels))
+	for tag := range config.LogLevels {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		level := config.LogLevels[tag]
+		if !isLogSubsystem(strings.ToUpper(tag)) {
+			errs = append(errs, "LogLevels has an unknown subsystem "+tag+
+				", should be one of "+strings.Join(logSubsystems, ", "))
+			continue
+		}
+		if !isLogLevel(level) {
+			errs = append(errs, fmt.Sprintf("LogLevels level %d of %s is not a log level", level, tag))
+			continue
+		}
+		if appCfg.LogLevels == nil {
+			appCfg.LogLevels = make(map[string]elalog.Level)
+		}
+		appCfg.LogLevels[strings.ToUpper(tag)] = level
+	}
+	if config.MaxLogsSize < 0 {
+		errs = append(errs, "MaxLogsSize can not be negative")
+	} else if config.MaxLogsSize > 0 {
+		appCfg.MaxLogsFolderSize = config.MaxLogsSize
+	}
+	if config.MaxPerLogSize < 0 {
+		errs = append(errs, "MaxPerLogSize can not be negative")
+	} else if config.MaxPerLogSize > 0 {
+		appCfg.MaxPerLogFileSize = config.MaxPerLogSize
+	}
+	if rotation := strings.ToLower(config.LogRotation); rotation != "" {
+		if rotation != logRotationDaily && rotation != logRotationHourly {
+			errs = append(errs, "LogRotation "+config.LogRotation+" should be daily or hourly")
+		} else {
+			appCfg.LogRotation = rotation
+		}
+	}
+	appCfg.CompressLogs = config.CompressLogs
+	if config.MaxLogAge < 0 {
+		errs = append(errs, "MaxLogAge can not be negative")
+	} else {
+		appCfg.MaxLogAge = time.Duration(config.MaxLogAge) * 24 * time.Hour
+	}
+	if format := config.LogFormat.Stdout; format != "" {
+		if !isLogFormat(format) {
+			errs = append(errs, "LogFormat.Stdout "+format+" should be text or json")
+		} else {
+			appCfg.StdoutLogFormat = format
+		}
+	}
+	if format := config.LogFormat.File; format != "" {
+		if !isLogFormat(format) {
+			errs = append(errs, "LogFormat.File "+format+" should be text or json")
+		} else {
+			appCfg.FileLogFormat = format
+		}
+	}
 
 	if config.Magic > 0 {
-		activeNetParams.Magic = config.Magic
+		chainParams.Magic = config.Magic
 	}
 	if config.SeedList != nil {
-		activeNetParams.SeedList = *config.SeedList
+		chainParams.SeedList = *config.SeedList
 	}
 	if config.NodePort > 0 {
-		activeNetParams.DefaultPort = config.NodePort
+		chainParams.DefaultPort = config.NodePort
 	}
 	if len(config.FoundationAddress) > 0 {
 		foundation, err := common.Uint168FromAddress(config.FoundationAddress)
-		if err == nil {
-			activeNetParams.Foundation = *foundation
+		if err != nil {
+			errs = append(errs, "invalid FoundationAddress "+config.FoundationAddress)
+		} else {
+			chainParams.Foundation = *foundation
 		}
 	}
-	if powCfg.MinTxFee > 0 {
-		activeNetParams.MinTransactionFee = powCfg.MinTxFee
+	if powCfg.MinTxFee < 0 {
+		errs = append(errs, "MinTxFee can not be negative")
+	} else if powCfg.MinTxFee > 0 {
+		chainParams.MinTransactionFee = powCfg.MinTxFee
 	}
-	if config.ExchangeRate > 0 {
-		activeNetParams.ExchangeRate = config.ExchangeRate
+	if config.ExchangeRate < 0 {
+		errs = append(errs, "ExchangeRate can not be negative")
+	} else if config.ExchangeRate > 0 {
+		chainParams.ExchangeRate = config.ExchangeRate
 	}
 	if config.DisableTxFilters {
-		activeNetParams.DisableTxFilters = true
+		chainParams.DisableTxFilters = true
 	}
-	if config.MinCrossChainTxFee > 0 {
-		activeNetParams.MinCrossChainTxFee = config.MinCrossChainTxFee
+	if config.MinCrossChainTxFee < 0 {
+		errs = append(errs, "MinCrossChainTxFee can not be negative")
+	} else if config.MinCrossChainTxFee > 0 {
+		chainParams.MinCrossChainTxFee = config.MinCrossChainTxFee
 	}
 	if config.SpvMagic > 0 {
-		activeNetParams.SpvParams.Magic = config.SpvMagic
+		chainParams.SpvParams.Magic = config.SpvMagic
 	}
 	if config.SpvSeedList != nil {
-		activeNetParams.SpvParams.SeedList = *config.SpvSeedList
+		chainParams.SpvParams.SeedList = *config.SpvSeedList
 	}
 	if len(config.MainChainFoundationAddress) > 0 {
-		activeNetParams.SpvParams.Foundation = config.MainChainFoundationAddress
+		chainParams.SpvParams.Foundation = config.MainChainFoundationAddress
 	}
 
+	errs = append(errs, applyGenesis(netType, chainParams, cfg)...)
+	errs = append(errs, applyCheckpoints(appCfg, cfg)...)
+
 	if powCfg.InstantBlock {
 		// generate block instantly
-		activeNetParams.PowLimitBits = 0x207fffff
-		activeNetParams.TargetTimespan = 1 * time.Second * 10
-		activeNetParams.TargetTimePerBlock = 1 * time.Second
-	} else {
-		activeNetParams.PowLimitBits = 0x1f0008ff
-		activeNetParams.TargetTimespan = 24 * time.Hour
-		activeNetParams.TargetTimePerBlock = 2 * time.Minute
+		chainParams.PowLimitBits = 0x207fffff
+		chainParams.TargetTimespan = 1 * time.Second * 10
+		chainParams.TargetTimePerBlock = 1 * time.Second
+	}
+
+	return errs
+}
+
+func isLogLevel(level elalog.Level) bool {
+	return level <= elalog.LevelFatal || level == elalog.LevelOff
+}
+
+// isLogFormat returns whether the format is one the log writers support.
+func isLogFormat(format string) bool {
+	return format == logFormatText || format == logFormatJSON
+}
+
+func isLogSubsystem(tag string) bool {
+	for _, subsystem := range logSubsystems {
+		if subsystem == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// applyGenesis replaces the genesis block of the regression test network by
+// the one loaded from the GenesisBlock file, or generated with the premines.
+func applyGenesis(netType string, chainParams *chaincfg.Params, cfg *config) []string {
+	config := cfg.Configuration
+	if config.GenesisBlock == "" && len(config.Premine) == 0 {
+		return nil
+	}
+	if netType != "RegNet" {
+		return []string{"GenesisBlock and Premine are only allowed on RegNet"}
+	}
+	if config.GenesisBlock != "" && len(config.Premine) > 0 {
+		return []string{"GenesisBlock and Premine can not be both set"}
+	}
+
+	if config.GenesisBlock != "" {
+		block, err := loadGenesisBlock(config.GenesisBlock)
+		if err != nil {
+			return []string{"invalid GenesisBlock " + config.GenesisBlock + ": " + err.Error()}
+		}
+		chainParams.GenesisBlock = block
+		return nil
+	}
+
+	var errs []string
+	premines := make([]params.Premine, 0, len(config.Premine))
+	for _, premine := range config.Premine {
+		programHash, err := common.Uint168FromAddress(premine.Address)
+		if err != nil {
+			errs = append(errs, "invalid Premine address "+premine.Address)
+			continue
+		}
+		if premine.Amount <= 0 {
+			errs = append(errs, "Premine amount of "+premine.Address+" must be positive")
+			continue
+		}
+		premines = append(premines, params.Premine{
+			ProgramHash: *programHash,
+			Value:       common.Fixed64(premine.Amount),
+		})
+	}
+	if len(errs) == 0 {
+		chainParams.GenesisBlock = params.GenerateRegNetGenesisBlock(premines)
+	}
+	return errs
+}
+
+// loadGenesisBlock reads a genesis block serialized in hex from the file.
+func loadGenesisBlock(path string) (*types.Block, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	block := new(types.Block)
+	if err := block.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	if block.Header.Height != 0 || !block.Header.Previous.IsEqual(common.EmptyHash) {
+		return nil, errors.New("not a genesis block")
+	}
+	return block, nil
+}
+
+// applyCheckpoints replaces the checkpoints of the network by the ones of the
+// config file, if any, and returns the problems with them.
+func applyCheckpoints(appCfg *appConfig, cfg *config) []string {
+	config := cfg.Configuration
+	if config.CheckpointScriptChecks {
+		appCfg.SkipCheckpointScripts = false
+	}
+	if config.DisableCheckpoints {
+		if len(config.Checkpoints) > 0 {
+			return []string{"Checkpoints are set while DisableCheckpoints is true"}
+		}
+		appCfg.Checkpoints = nil
+		return nil
+	}
+	if len(config.Checkpoints) == 0 {
+		return nil
+	}
+
+	var errs []string
+	checkpoints := make(params.Checkpoints, 0, len(config.Checkpoints))
+	for i, checkpoint := range config.Checkpoints {
+		height := strconv.FormatUint(uint64(checkpoint.Height), 10)
+		if i > 0 && checkpoint.Height <= config.Checkpoints[i-1].Height {
+			errs = append(errs, "checkpoint at height "+height+" is not in ascending height order")
+		}
+		hashBytes, err := common.HexStringToBytes(checkpoint.Hash)
+		if err != nil {
+			errs = append(errs, "invalid hash of the checkpoint at height "+height)
+			continue
+		}
+		hash, err := common.Uint256FromBytes(common.BytesReverse(hashBytes))
+		if err != nil {
+			errs = append(errs, "invalid hash of the checkpoint at height "+height)
+			continue
+		}
+		checkpoints = append(checkpoints, params.Checkpoint{Height: checkpoint.Height, Hash: *hash})
+	}
+	if len(errs) == 0 {
+		appCfg.Checkpoints = checkpoints
+	}
+	return errs
+}
+
+// applyFlags overrides the values set by the flags, and returns the problems
+// with the values that can not be applied.
+func applyFlags(appCfg *appConfig, setFlags map[string]bool) []string {
+	var errs []string
+
+	appCfg.LogDir = filepath.Join(*dataDir, defaultLogDir)
+	if setFlags["logdir"] {
+		appCfg.LogDir = *logDir
+	}
+
+	ports := []struct {
+		name string
+		flag *uint
+		port *uint16
+	}{
+		{"rpcport", rpcPort, &appCfg.HttpJsonPort},
+		{"restport", restPort, &appCfg.HttpRestPort},
+		{"wsport", wsPort, &appCfg.HttpWsPort},
+	}
+	for _, p := range ports {
+		if !setFlags[p.name] {
+			continue
+		}
+		if *p.flag > 0xffff {
+			errs = append(errs, fmt.Sprintf("%s %d is not a port", p.name, *p.flag))
+			continue
+		}
+		*p.port = uint16(*p.flag)
+	}
+
+	if setFlags["mining"] {
+		appCfg.Mining = *mining
+	}
+	if setFlags["paytoaddr"] {
+		appCfg.MinerAddr = *payToAddr
+	}
+
+	return errs
+}
+
+// validateConfig returns the problems with the resolved configuration.
+func validateConfig(netType string, appCfg *appConfig, chainParams *chaincfg.Params) []string {
+	var errs []string
+
+	if _, err := common.Uint168FromAddress(appCfg.MinerAddr); err != nil {
+		errs = append(errs, "invalid PayToAddr "+appCfg.MinerAddr)
+	}
+	if _, err := common.Uint168FromAddress(chainParams.SpvParams.Foundation); err != nil {
+		errs = append(errs, "invalid MainChainFoundationAddress "+chainParams.SpvParams.Foundation)
+	}
+	if _, ok := elalog.LevelFromString(appCfg.LogLevel); !ok {
+		errs = append(errs, "invalid log level "+appCfg.LogLevel)
 	}
 
-	activeNetParams.ElaAssetId = params.ElaAssetId
-	activeNetParams.AdjustmentFactor = 4
-	activeNetParams.CoinbaseMaturity = 100
-	activeNetParams.MinTransactionFee = 100
-	activeNetParams.ExchangeRate = 1
-	activeNetParams.MinCrossChainTxFee = 10000
-	activeNetParams.GenesisBlock = params.GenesisBlock
+	ports := []struct {
+		name string
+		port uint16
+	}{
+		{"NodePort", chainParams.DefaultPort},
+		{"HttpRestPort", appCfg.HttpRestPort},
+		{"HttpJsonPort", appCfg.HttpJsonPort},
+		{"HttpWsPort", appCfg.HttpWsPort},
+	}
+	for i, p := range ports {
+		if p.port == 0 {
+			errs = append(errs, p.name+" is not set")
+			continue
+		}
+		for _, q := range ports[:i] {
+			if p.port == q.port {
+				errs = append(errs, fmt.Sprintf("%s and %s both use port %d",
+					q.name, p.name, p.port))
+			}
+		}
+	}
 
-	return &appCfg, nil
+	if chainParams.Magic == chainParams.SpvParams.Magic {
+		errs = append(errs, fmt.Sprintf("Magic and SpvMagic both are %d", chainParams.Magic))
+	}
+	// The networks are checked in name order, for the errors to keep the
+	// same order from one start to the next.
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != netType && chainParams.Magic == networks[name].Magic {
+			errs = append(errs, fmt.Sprintf("Magic %d is the magic of %s", chainParams.Magic, name))
+		}
+	}
+	return errs
 }
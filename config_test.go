@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+
+	"github.com/elastos/Elastos.ELA.Utility/elalog"
+)
+
+func TestConfigErrorOrder(t *testing.T) {
+	fileCfg := new(config)
+	fileCfg.Configuration.LogLevels = map[string]elalog.Level{
+		"zzz": elalog.LevelInfo,
+		"aaa": elalog.LevelInfo,
+		"mmm": elalog.LevelInfo,
+	}
+
+	// The errors of the map entries come in key order, every time.
+	for i := 0; i < 10; i++ {
+		chainParams := params.RegNetParams
+		errs := applyConfigFile("RegNet", defaultAppConfig("RegNet"), &chainParams, fileCfg)
+		var subsystems []string
+		for _, err := range errs {
+			for _, tag := range []string{"aaa", "mmm", "zzz"} {
+				if strings.Contains(err, "subsystem "+tag) {
+					subsystems = append(subsystems, tag)
+				}
+			}
+		}
+		if strings.Join(subsystems, ",") != "aaa,mmm,zzz" {
+			t.Fatalf("errors of the unknown subsystems in order %v", subsystems)
+		}
+	}
+
+	chainParams := params.RegNetParams
+	chainParams.Magic = params.MainNetParams.Magic
+	errs := validateConfig("RegNet", defaultAppConfig("RegNet"), &chainParams)
+	if len(errs) != 1 || !strings.Contains(errs[0], "MainNet") {
+		t.Errorf("errors of a magic of another network %v", errs)
+	}
+}
+
+func TestParseFlagSet(t *testing.T) {
+	newFlags := func() (*flag.FlagSet, *uint, *string, *bool) {
+		flags := flag.NewFlagSet("did", flag.ContinueOnError)
+		port := flags.Uint("rpcport", 0, "")
+		addr := flags.String("paytoaddr", "", "")
+		mine := flags.Bool("mining", false, "")
+		return flags, port, addr, mine
+	}
+	setenv := func(name, value string) func() {
+		os.Setenv(name, value)
+		return func() { os.Unsetenv(name) }
+	}
+	defer setenv("DID_RPCPORT", "30000")()
+	defer setenv("DID_PAYTOADDR", "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3")()
+
+	// A flag of the command line takes precedence over the environment.
+	flags, port, addr, mine := newFlags()
+	setFlags, errs := parseFlagSet(flags, []string{"-rpcport", "20000"})
+	if len(errs) != 0 {
+		t.Fatal("parse flags errors:", errs)
+	}
+	if *port != 20000 || *addr != "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3" || *mine {
+		t.Errorf("flags rpcport %d, paytoaddr %s, mining %v", *port, *addr, *mine)
+	}
+	if !setFlags["rpcport"] || !setFlags["paytoaddr"] || setFlags["mining"] {
+		t.Errorf("set flags %v", setFlags)
+	}
+
+	// A value of the environment which does not parse is reported.
+	defer setenv("DID_MINING", "often")()
+	flags, _, _, _ = newFlags()
+	if _, errs = parseFlagSet(flags, nil); len(errs) != 1 || !strings.Contains(errs[0], "DID_MINING") {
+		t.Errorf("parse flags errors %v", errs)
+	}
+}
+
+func TestApplyFlags(t *testing.T) {
+	defer func(port uint, addr string) {
+		*rpcPort, *payToAddr = port, addr
+	}(*rpcPort, *payToAddr)
+
+	// The flags only override the config file values when they are set.
+	fileCfg := new(config)
+	fileCfg.Configuration.HttpJsonPort = 30000
+	fileCfg.Configuration.PowConfiguration.PayToAddr = "8VYXVxKKSAxkmRrfmGpQR2Kc66XhG6m3ta"
+	chainParams := params.RegNetParams
+	appCfg := defaultAppConfig("RegNet")
+	applyConfigFile("RegNet", appCfg, &chainParams, fileCfg)
+
+	*rpcPort, *payToAddr = 40000, "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3"
+	if errs := applyFlags(appCfg, map[string]bool{"rpcport": true}); len(errs) != 0 {
+		t.Fatal("apply flags errors:", errs)
+	}
+	if appCfg.HttpJsonPort != 40000 || appCfg.MinerAddr != "8VYXVxKKSAxkmRrfmGpQR2Kc66XhG6m3ta" {
+		t.Errorf("rpc port %d, miner address %s", appCfg.HttpJsonPort, appCfg.MinerAddr)
+	}
+
+	*rpcPort = 70000
+	if errs := applyFlags(appCfg, map[string]bool{"rpcport": true}); len(errs) != 1 {
+		t.Errorf("apply flags errors %v", errs)
+	}
+}
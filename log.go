@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"io"
 	"os"
-	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/elastos/Elastos.ELA.SideChain/blockchain"
 	"github.com/elastos/Elastos.ELA.SideChain/mempool"
@@ -36,17 +38,47 @@ func configFileWriter() (string, int64, int64) {
 	if cfg.MaxLogsFolderSize > 0 {
 		maxLogsFolderSize = cfg.MaxLogsFolderSize * elalog.MBSize
 	}
-	return filepath.Join(DataPath, defaultLogDir), maxPerLogFileSize, maxLogsFolderSize
+	return cfg.LogDir, maxPerLogFileSize, maxLogsFolderSize
+}
+
+// newLogFileWriter returns the writer of the log files, which rotates them
+// by size only unless a rotation period, compression or max age is set.
+func newLogFileWriter() io.Writer {
+	if cfg.LogRotation == "" && !cfg.CompressLogs && cfg.MaxLogAge == 0 {
+		return elalog.NewFileWriter(configFileWriter())
+	}
+	dir, maxPerLogFileSize, maxLogsFolderSize := configFileWriter()
+	return newRotatingFileWriter(dir, maxPerLogFileSize, maxLogsFolderSize,
+		cfg.LogRotation, cfg.CompressLogs, cfg.MaxLogAge)
+}
+
+// logOutput is the output of the loggers. The log files and formats are
+// only known once the configuration is loaded, the records written before
+// go to stdout.
+type logOutput struct {
+	sync.RWMutex
+	writer io.Writer
+}
+
+func (o *logOutput) Write(p []byte) (int, error) {
+	o.RLock()
+	defer o.RUnlock()
+	return o.writer.Write(p)
+}
+
+func (o *logOutput) set(writer io.Writer) {
+	o.Lock()
+	o.writer = writer
+	o.Unlock()
 }
 
 // log is a logger that is initialized with no output filters.  This
 // means the package will not perform any logging by default until the caller
 // requests it.
 var (
-	fileWriter = elalog.NewFileWriter(configFileWriter())
-	logWriter  = io.MultiWriter(os.Stdout, fileWriter)
-	backend    = elalog.NewBackend(logWriter, elalog.Llongfile)
-	level, _   = elalog.LevelFromString(cfg.LogLevel)
+	logWriter = &logOutput{writer: os.Stdout}
+	backend   = elalog.NewBackend(logWriter, elalog.Llongfile)
+	level, _  = elalog.LevelFromString(defaultLogLevel)
 
 	admrlog = backend.Logger("ADMR", elalog.LevelOff)
 	cmgrlog = backend.Logger("CMGR", elalog.LevelOff)
@@ -63,6 +95,64 @@ var (
 	eladlog = backend.Logger("ELAD", level)
 )
 
+// logSubsystems are the tags of the subsystem loggers, whose level can be
+// set on its own.
+var logSubsystems = []string{"ADMR", "CMGR", "BCDB", "TXMP", "SYNC", "PEER",
+	"MINR", "SPVS", "SRVR", "HTTP", "RPCS", "REST", "ELAD"}
+
+// subsystemLogger returns the logger of the subsystem tag.
+func subsystemLogger(tag string) (elalog.Logger, bool) {
+	switch tag {
+	case "ADMR":
+		return admrlog, true
+	case "CMGR":
+		return cmgrlog, true
+	case "BCDB":
+		return bcdblog, true
+	case "TXMP":
+		return txmplog, true
+	case "SYNC":
+		return synclog, true
+	case "PEER":
+		return peerlog, true
+	case "MINR":
+		return minrlog, true
+	case "SPVS":
+		return spvslog, true
+	case "SRVR":
+		return srvrlog, true
+	case "HTTP":
+		return httplog, true
+	case "RPCS":
+		return rpcslog, true
+	case "REST":
+		return restlog, true
+	case "ELAD":
+		return eladlog, true
+	}
+	return nil, false
+}
+
+// setSubsystemLogLevel sets the level of the logger of the subsystem tag.
+func setSubsystemLogLevel(tag string, level elalog.Level) error {
+	logger, ok := subsystemLogger(strings.ToUpper(tag))
+	if !ok {
+		return errors.New("unknown log subsystem " + tag + ", should be one of " +
+			strings.Join(logSubsystems, ", "))
+	}
+	logger.SetLevel(level)
+	return nil
+}
+
+// applyLogLevels sets every logger but ADMR and CMGR to the level, then the
+// subsystems given their own level to it.
+func applyLogLevels(level elalog.Level, levels map[string]elalog.Level) {
+	setLogLevel(level)
+	for tag, level := range levels {
+		setSubsystemLogLevel(tag, level)
+	}
+}
+
 func setLogLevel(level elalog.Level) {
 	bcdblog.SetLevel(level)
 	txmplog.SetLevel(level)
@@ -92,3 +182,12 @@ func init() {
 	jsonrpc.UseLogger(rpcslog)
 	restful.UseLogger(restlog)
 }
+
+// initLogging sets the output and the levels of the loggers from the loaded
+// configuration.
+func initLogging() {
+	logWriter.set(io.MultiWriter(newLogFormatWriter(os.Stdout, cfg.StdoutLogFormat),
+		newLogFormatWriter(newLogFileWriter(), cfg.FileLogFormat)))
+	level, _ := elalog.LevelFromString(cfg.LogLevel)
+	applyLogLevels(level, cfg.LogLevels)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logFormatText writes the log records as elalog formats them.
+	logFormatText = "text"
+
+	// logFormatJSON writes every log record as a JSON object on its own line.
+	logFormatJSON = "json"
+)
+
+// logTimeLayouts are the layouts of the timestamps of the elalog records,
+// the fractional seconds are parsed without being in the layout.
+var logTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+// logRecordPattern matches an elalog record: the timestamp, the level, the
+// subsystem tag, the optional file and line of the Llongfile flag and the
+// message.
+var logRecordPattern = regexp.MustCompile(
+	`^(\d{4}[/-]\d{2}[/-]\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?) \[(\w+)\] (\w+): (?:(\S+\.go):(\d+):? )?(.*)$`)
+
+// logFieldPattern matches a key=value field in the message of a record.
+var logFieldPattern = regexp.MustCompile(`(\w+)=("[^"]*"|\S+)`)
+
+// logLevelNames maps the level abbreviations of elalog to level names.
+var logLevelNames = map[string]string{
+	"DBG": "debug",
+	"INF": "info",
+	"WRN": "warn",
+	"ERR": "error",
+	"FTL": "fatal",
+}
+
+// jsonLogRecord is a log record written in the JSON format.
+type jsonLogRecord struct {
+	Time      string            `json:"time,omitempty"`
+	Level     string            `json:"level,omitempty"`
+	Subsystem string            `json:"subsystem,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// jsonLogWriter converts the text log records written to it into JSON
+// records written to w. A line which is not a log record is written as a
+// record with only a message.
+type jsonLogWriter struct {
+	sync.Mutex
+	w       io.Writer
+	partial []byte
+}
+
+// newLogFormatWriter returns a writer writing the log records to w in the
+// format.
+func newLogFormatWriter(w io.Writer, format string) io.Writer {
+	if format == logFormatJSON {
+		return &jsonLogWriter{w: w}
+	}
+	return w
+}
+
+func (j *jsonLogWriter) Write(p []byte) (int, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	data := append(j.partial, p...)
+	j.partial = nil
+	var out bytes.Buffer
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break
+		}
+		line := string(data[:end])
+		data = data[end+1:]
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		record, err := json.Marshal(parseLogRecord(line))
+		if err != nil {
+			return 0, err
+		}
+		out.Write(record)
+		out.WriteByte('\n')
+	}
+	// Keep the start of a record whose end is not written yet.
+	if len(data) > 0 {
+		j.partial = append([]byte(nil), data...)
+	}
+
+	if out.Len() > 0 {
+		if _, err := j.w.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// parseLogRecord splits a text log record into the fields of a JSON record.
+func parseLogRecord(line string) *jsonLogRecord {
+	match := logRecordPattern.FindStringSubmatch(line)
+	if match == nil {
+		return &jsonLogRecord{Message: line}
+	}
+
+	record := &jsonLogRecord{
+		Time:      formatLogTime(match[1]),
+		Level:     match[2],
+		Subsystem: match[3],
+		Message:   match[6],
+	}
+	if name, ok := logLevelNames[match[2]]; ok {
+		record.Level = name
+	}
+	fields := make(map[string]string)
+	for _, field := range logFieldPattern.FindAllStringSubmatch(record.Message, -1) {
+		fields[field[1]] = strings.Trim(field[2], `"`)
+	}
+	if match[4] != "" {
+		fields["file"] = match[4]
+		fields["line"] = match[5]
+	}
+	if len(fields) > 0 {
+		record.Fields = fields
+	}
+	return record
+}
+
+// formatLogTime returns the timestamp of a record in RFC 3339, or as it is if
+// its layout is unknown.
+func formatLogTime(timestamp string) string {
+	for _, layout := range logTimeLayouts {
+		t, err := time.ParseInLocation(layout, timestamp, time.Local)
+		if err == nil {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+	return timestamp
+}
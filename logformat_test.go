@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogRecord(t *testing.T) {
+	record := parseLogRecord(`2019-03-05 10:20:30.123 [WRN] BLCH: main.go:42: block rejected height=12 reason="bad proof"`)
+	timestamp, _ := time.ParseInLocation("2006-01-02 15:04:05", "2019-03-05 10:20:30.123", time.Local)
+	if record.Time != timestamp.Format(time.RFC3339Nano) || record.Level != "warn" || record.Subsystem != "BLCH" {
+		t.Errorf("record time %s, level %s, subsystem %s", record.Time, record.Level, record.Subsystem)
+	}
+	if record.Message != `block rejected height=12 reason="bad proof"` {
+		t.Errorf("record message %s", record.Message)
+	}
+	fields := record.Fields
+	if len(fields) != 4 || fields["height"] != "12" || fields["reason"] != "bad proof" ||
+		fields["file"] != "main.go" || fields["line"] != "42" {
+		t.Errorf("record fields %v", fields)
+	}
+
+	// A level elalog does not abbreviate is kept, a line which is not a
+	// record is only a message.
+	if record = parseLogRecord("2019/03/05 10:20:30 [TRC] RPCS: started"); record.Level != "TRC" || record.Fields != nil {
+		t.Errorf("record level %s, fields %v", record.Level, record.Fields)
+	}
+	if record = parseLogRecord("panic: runtime error"); record.Message != "panic: runtime error" ||
+		record.Time != "" || record.Level != "" {
+		t.Errorf("record of a line which is not a record %+v", record)
+	}
+}
+
+func TestJSONLogWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newLogFormatWriter(&out, logFormatJSON)
+
+	// A record written in parts is written once its line ends, the empty
+	// lines are left out.
+	w.Write([]byte("2019-03-05 10:20:30 [INF] ELAD: sync"))
+	if out.Len() != 0 {
+		t.Fatal("partial record should not be written!")
+	}
+	w.Write([]byte("ing height=3\n\n2019-03-05 10:20:31 [ERR] ELAD: fail\n"))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("written lines %q", lines)
+	}
+	var first, second jsonLogRecord
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal("unmarshal record error:", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal("unmarshal record error:", err)
+	}
+	if first.Message != "syncing height=3" || first.Fields["height"] != "3" || second.Level != "error" {
+		t.Errorf("written records %+v, %+v", first, second)
+	}
+
+	// The text format writes the records as they are.
+	out.Reset()
+	newLogFormatWriter(&out, logFormatText).Write([]byte("line\n"))
+	if out.String() != "line\n" {
+		t.Errorf("text record %q", out.String())
+	}
+}
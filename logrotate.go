@@ -0,0 +1,226 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logRotationDaily starts a new log file at every local midnight.
+	logRotationDaily = "daily"
+
+	// logRotationHourly starts a new log file at every hour.
+	logRotationHourly = "hourly"
+
+	// logFileLayout is the layout of the names of the log files.
+	logFileLayout = "2006-01-02_15.04.05"
+)
+
+// rotatingFileWriter writes the log records to files in a folder. It starts a
+// new file when the current one is full or its rotation period ended,
+// compresses the files it stops writing to, and removes the files older than
+// the max age or above the folder size, the oldest first.
+type rotatingFileWriter struct {
+	sync.Mutex
+	dir           string
+	maxFileSize   int64
+	maxFolderSize int64
+	rotation      string
+	compress      bool
+	maxAge        time.Duration
+
+	file       *os.File
+	size       int64
+	rotateTime time.Time
+
+	// cleaning serializes the compression and the removal of the files
+	// which run apart from the writes, and cleanups counts the ones not
+	// done yet.
+	cleaning sync.Mutex
+	cleanups sync.WaitGroup
+}
+
+func newRotatingFileWriter(dir string, maxFileSize, maxFolderSize int64,
+	rotation string, compress bool, maxAge time.Duration) *rotatingFileWriter {
+	return &rotatingFileWriter{
+		dir:           dir,
+		maxFileSize:   maxFileSize,
+		maxFolderSize: maxFolderSize,
+		rotation:      rotation,
+		compress:      compress,
+		maxAge:        maxAge,
+	}
+}
+
+func (w *rotatingFileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	now := time.Now()
+	// A record larger than a file is written to a file of its own.
+	if w.file == nil || (w.size > 0 && w.size+int64(len(p)) > w.maxFileSize) ||
+		(!w.rotateTime.IsZero() && !now.Before(w.rotateTime)) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate closes the current log file and opens a new one.
+func (w *rotatingFileWriter) rotate(now time.Time) error {
+	if err := os.MkdirAll(w.dir, 0740); err != nil {
+		return err
+	}
+
+	var closed string
+	if w.file != nil {
+		closed = w.file.Name()
+		w.file.Close()
+		w.file = nil
+	}
+
+	name, file, err := createLogFile(w.dir, now)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.size = 0
+	w.rotateTime = nextLogRotation(now, w.rotation)
+
+	w.cleanups.Add(1)
+	go func() {
+		defer w.cleanups.Done()
+		w.clean(closed, name)
+	}()
+	return nil
+}
+
+// clean compresses the closed log file, if any, and removes the log files
+// beyond the retention limits, except the current one.
+func (w *rotatingFileWriter) clean(closed, current string) {
+	w.cleaning.Lock()
+	defer w.cleaning.Unlock()
+
+	if closed != "" && closed != current && w.compress {
+		if err := compressLogFile(closed); err != nil {
+			eladlog.Warnf("Compress log file %s failed: %s", closed, err)
+		}
+	}
+
+	infos, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		eladlog.Warnf("Read log folder %s failed: %s", w.dir, err)
+		return
+	}
+	var files []os.FileInfo
+	var folderSize int64
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || filepath.Join(w.dir, name) == current ||
+			!(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			continue
+		}
+		files = append(files, info)
+		folderSize += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	now := time.Now()
+	for _, info := range files {
+		expired := w.maxAge > 0 && now.Sub(info.ModTime()) > w.maxAge
+		if !expired && folderSize <= w.maxFolderSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(w.dir, info.Name())); err != nil {
+			eladlog.Warnf("Remove log file %s failed: %s", info.Name(), err)
+			continue
+		}
+		folderSize -= info.Size()
+	}
+}
+
+// createLogFile creates a new log file named after the time it is opened at.
+// The files opened in the same second are told apart by a sequence number,
+// so a file is never opened again.
+func createLogFile(dir string, now time.Time) (string, *os.File, error) {
+	prefix := filepath.Join(dir, now.Format(logFileLayout))
+	for sequence := 0; ; sequence++ {
+		name := prefix + ".log"
+		if sequence > 0 {
+			name = prefix + "_" + strconv.Itoa(sequence) + ".log"
+		}
+		file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+		if os.IsExist(err) {
+			continue
+		}
+		return name, file, err
+	}
+}
+
+// nextLogRotation returns when the file opened at now is rotated, or the zero
+// time if only its size rotates it.
+func nextLogRotation(now time.Time, rotation string) time.Time {
+	year, month, day := now.Date()
+	switch rotation {
+	case logRotationDaily:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+	case logRotationHourly:
+		return time.Date(year, month, day, now.Hour()+1, 0, 0, 0, now.Location())
+	}
+	return time.Time{}
+}
+
+// compressLogFile replaces the log file with its gzip compression, which
+// keeps the modification time of the log file.
+func compressLogFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(name)
+	zw.ModTime = info.ModTime()
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := os.Chtimes(name+".gz", info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
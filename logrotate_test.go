@@ -0,0 +1,186 @@
+package main
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLogFolder returns a temporary log folder and the function removing
+// it.
+func newTestLogFolder(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "idlogs")
+	if err != nil {
+		t.Fatal("create log folder error:", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// logFileContents returns the contents of the files of the log folder, in
+// name order.
+func logFileContents(t *testing.T, dir string) map[string]string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal("read log folder error:", err)
+	}
+	contents := make(map[string]string)
+	for _, info := range infos {
+		data, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			t.Fatal("read log file error:", err)
+		}
+		contents[info.Name()] = string(data)
+	}
+	return contents
+}
+
+func writeLog(t *testing.T, w *rotatingFileWriter, record string) {
+	if _, err := w.Write([]byte(record)); err != nil {
+		t.Fatal("write log error:", err)
+	}
+}
+
+func TestLogSizeRotation(t *testing.T) {
+	dir, remove := newTestLogFolder(t)
+	defer remove()
+
+	// The files opened in the same second get names of their own, so a
+	// full file is never opened again.
+	w := newRotatingFileWriter(dir, 10, 1<<20, "", false, 0)
+	writeLog(t, w, "record 1\n")
+	writeLog(t, w, "record 2\n")
+	writeLog(t, w, "a record larger than a file\n")
+	writeLog(t, w, "record 3\n")
+	w.cleanups.Wait()
+
+	var records []string
+	for name, content := range logFileContents(t, dir) {
+		if !strings.HasSuffix(name, ".log") {
+			t.Errorf("log file name %s", name)
+		}
+		records = append(records, content)
+	}
+	if len(records) != 4 {
+		t.Fatalf("log files %v", records)
+	}
+	for _, record := range records {
+		if strings.Count(record, "\n") != 1 {
+			t.Errorf("log file records %q", record)
+		}
+	}
+}
+
+func TestLogTimeRotation(t *testing.T) {
+	now := time.Date(2019, 3, 5, 10, 20, 30, 0, time.Local)
+	if next := nextLogRotation(now, logRotationDaily); !next.Equal(time.Date(2019, 3, 6, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("daily rotation at %s", next)
+	}
+	if next := nextLogRotation(now, logRotationHourly); !next.Equal(time.Date(2019, 3, 5, 11, 0, 0, 0, time.Local)) {
+		t.Errorf("hourly rotation at %s", next)
+	}
+	if next := nextLogRotation(now, ""); !next.IsZero() {
+		t.Errorf("size rotation at %s", next)
+	}
+
+	dir, remove := newTestLogFolder(t)
+	defer remove()
+
+	w := newRotatingFileWriter(dir, 1<<20, 1<<20, logRotationHourly, false, 0)
+	writeLog(t, w, "record 1\n")
+	writeLog(t, w, "record 2\n")
+	w.cleanups.Wait()
+	if contents := logFileContents(t, dir); len(contents) != 1 {
+		t.Fatalf("log files before the rotation time %v", contents)
+	}
+
+	// The hour ended.
+	w.Lock()
+	w.rotateTime = time.Now()
+	w.Unlock()
+	writeLog(t, w, "record 3\n")
+	w.cleanups.Wait()
+	if contents := logFileContents(t, dir); len(contents) != 2 {
+		t.Errorf("log files after the rotation time %v", contents)
+	}
+}
+
+func TestLogCompression(t *testing.T) {
+	dir, remove := newTestLogFolder(t)
+	defer remove()
+
+	closed := filepath.Join(dir, "2019-03-05_10.20.30.log")
+	current := filepath.Join(dir, "2019-03-05_10.20.30_1.log")
+	ioutil.WriteFile(closed, []byte("record 1\n"), 0640)
+	ioutil.WriteFile(current, []byte("record 2\n"), 0640)
+
+	w := newRotatingFileWriter(dir, 1<<20, 1<<20, "", true, 0)
+	w.clean(current, current)
+	if contents := logFileContents(t, dir); len(contents) != 2 {
+		t.Fatalf("current log file should not be compressed! %v", contents)
+	}
+
+	w.clean(closed, current)
+	contents := logFileContents(t, dir)
+	if _, ok := contents[filepath.Base(closed)]; ok || len(contents) != 2 {
+		t.Fatalf("log files after the compression %v", contents)
+	}
+	file, err := os.Open(closed + ".gz")
+	if err != nil {
+		t.Fatal("open compressed log file error:", err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal("read compressed log file error:", err)
+	}
+	if data, err := ioutil.ReadAll(zr); err != nil || string(data) != "record 1\n" {
+		t.Errorf("compressed log file %q, error %v", data, err)
+	}
+}
+
+func TestLogPruning(t *testing.T) {
+	dir, remove := newTestLogFolder(t)
+	defer remove()
+
+	now := time.Now()
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"2019-03-01_00.00.00.log.gz", 72 * time.Hour},
+		{"2019-03-02_00.00.00.log", 48 * time.Hour},
+		{"2019-03-03_00.00.00.log", 12 * time.Hour},
+		{"2019-03-04_00.00.00.log", time.Hour},
+		{"notes.txt", 72 * time.Hour},
+	}
+	for _, file := range files {
+		name := filepath.Join(dir, file.name)
+		ioutil.WriteFile(name, []byte("record\n"), 0640)
+		os.Chtimes(name, now.Add(-file.age), now.Add(-file.age))
+	}
+	current := filepath.Join(dir, files[3].name)
+
+	// The files older than a day are removed.
+	w := newRotatingFileWriter(dir, 1<<20, 1<<20, "", false, 24*time.Hour)
+	w.clean("", current)
+	contents := logFileContents(t, dir)
+	if len(contents) != 3 {
+		t.Fatalf("log files after the age pruning %v", contents)
+	}
+	if _, ok := contents[files[2].name]; !ok {
+		t.Errorf("log file younger than a day should be kept! %v", contents)
+	}
+
+	// The oldest files are removed above the folder size, never the current
+	// one.
+	w = newRotatingFileWriter(dir, 1<<20, 0, "", false, 0)
+	w.clean("", current)
+	contents = logFileContents(t, dir)
+	if _, ok := contents[files[3].name]; !ok || len(contents) != 2 {
+		t.Errorf("log files after the size pruning %v", contents)
+	}
+}
@@ -3,17 +3,20 @@ package main
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"github.com/elastos/Elastos.ELA.SideChain/service/websocket"
 	"os"
+	ossignal "os/signal"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
 	"strconv"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	bc "github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
 	mp "github.com/elastos/Elastos.ELA.SideChain.ID/mempool"
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
 	sv "github.com/elastos/Elastos.ELA.SideChain.ID/service"
 
 	"github.com/elastos/Elastos.ELA.SideChain/blockchain"
@@ -33,7 +36,10 @@ import (
 const (
 	printStateInterval = time.Minute
 
-	DataPath = "elastos_did"
+	// reindexLogInterval is the number of blocks between two progress logs
+	// of a rebuild of the identification indexes.
+	reindexLogInterval = 1000
+
 	DataDir  = "data"
 	ChainDir = "chain"
 	SpvDir   = "spv"
@@ -45,6 +51,12 @@ var (
 
 	// The go source code version at build.
 	GoVersion string
+
+	// DataPath is the directory of the node data, set by the datadir flag.
+	DataPath = defaultDataPath
+
+	// monitorState is 1 when the sync state is logged.
+	monitorState int32
 )
 
 func main() {
@@ -57,6 +69,10 @@ func main() {
 	// usage.
 	debug.SetGCPercent(10)
 
+	var loadConfigErr error
+	cfg, loadConfigErr = loadNewConfig()
+	initLogging()
+
 	eladlog.Infof("Node version: %s", Version)
 	eladlog.Info(GoVersion)
 
@@ -70,13 +86,34 @@ func main() {
 
 	eladlog.Info("1. BlockChain init")
 	idChainStore, err := bc.NewChainStore(activeNetParams.GenesisBlock,
-		filepath.Join(DataPath, DataDir, ChainDir))
+		params.GetActivations(activeNetParams), cfg.Checkpoints, filepath.Join(DataPath, DataDir, ChainDir))
 	if err != nil {
 		eladlog.Fatalf("open chain store failed, %s", err)
 		os.Exit(1)
 	}
 	defer idChainStore.Close()
 
+	if *reindex || idChainStore.ReindexInProgress() {
+		eladlog.Info("Rebuild the identification indexes")
+		err := idChainStore.Reindex(interrupt.C, func(height, bestHeight uint32) {
+			if height%reindexLogInterval == 0 || height == bestHeight {
+				eladlog.Infof("Reindexed block %d of %d", height, bestHeight)
+			}
+		})
+		if err == bc.ErrReindexInterrupted {
+			eladlog.Info("Reindex interrupted, it resumes on the next start")
+			return
+		}
+		if err != nil {
+			eladlog.Fatalf("reindex failed, %s", err)
+			os.Exit(1)
+		}
+	}
+
+	if !idChainStore.HasStateTree() {
+		eladlog.Warn("The identification state tree is not built, state roots and proofs are not served until -reindex")
+	}
+
 	chainCfg := blockchain.Config{
 		ChainParams: activeNetParams,
 		ChainStore:  idChainStore.ChainStore,
@@ -122,10 +159,10 @@ func main() {
 	defer spvService.Stop()
 	spvService.Start()
 
-	txValidator := mp.NewValidator(&mempoolCfg)
-	mempoolCfg.Validator = txValidator
-	chainCfg.CheckTxSanity = txValidator.CheckTransactionSanity
-	chainCfg.CheckTxContext = txValidator.CheckTransactionContext
+	mempoolCfg.Validator = mp.NewValidator(&mempoolCfg, idChainStore)
+	blockValidator := mp.NewBlockValidator(&mempoolCfg, idChainStore, cfg.SkipCheckpointScripts)
+	chainCfg.CheckTxSanity = blockValidator.CheckTransactionSanity
+	chainCfg.CheckTxContext = blockValidator.CheckTransactionContext
 
 	chain, err := blockchain.New(&chainCfg)
 	if err != nil {
@@ -133,6 +170,9 @@ func main() {
 		os.Exit(1)
 	}
 	chainCfg.Validator = blockchain.NewValidator(chain)
+	chainCfg.Validator.RegisterFunc(bc.CheckIdentificationBlockContext, idChainStore.CheckBlockContext)
+	chainCfg.Validator.RegisterFunc(bc.CheckCoinbaseReward, bc.NewCoinbaseRewardCheck(
+		activeNetParams.Foundation, params.GetRewardSchedule(activeNetParams)))
 
 	txPool := mempool.New(&mempoolCfg)
 
@@ -146,6 +186,7 @@ func main() {
 	server.Start()
 
 	eladlog.Info("4. --Initialize pow service")
+	miner := newMinerState(cfg, idChainStore)
 	powCfg := pow.Config{
 		ChainParams:               activeNetParams,
 		MinerAddr:                 cfg.MinerAddr,
@@ -154,12 +195,13 @@ func main() {
 		Chain:                     chain,
 		TxMemPool:                 txPool,
 		TxFeeHelper:               txFeeHelper,
-		CreateCoinBaseTx:          pow.CreateCoinBaseTx,
-		GenerateBlock:             pow.GenerateBlock,
-		GenerateBlockTransactions: pow.GenerateBlockTransactions,
+		CreateCoinBaseTx:          miner.createCoinBaseTx,
+		GenerateBlock:             miner.generateBlock,
+		GenerateBlockTransactions: miner.generateBlockTransactions,
 	}
 
 	powService := pow.NewService(&powCfg)
+	miner.powService = powService
 	if cfg.Mining {
 		eladlog.Info("Start POW Services")
 		go powService.Start()
@@ -181,9 +223,14 @@ func main() {
 		GetTransaction:              service.GetTransaction,
 		GetPayloadInfo:              sv.GetPayloadInfo,
 		GetPayload:                  service.GetPayload,
-	}, idChainStore)
+	}, idChainStore, setSubsystemLogLevel)
 
-	rpcServer := newJsonRpcServer(cfg.HttpJsonPort, service)
+	rpcAllowList := newRPCAllowList()
+	rpcServer := newJsonRpcServer(cfg.HttpJsonPort, service, rpcAllowList, miner)
+	if err := rpcAllowList.set(cfg.RpcAllowedActions); err != nil {
+		eladlog.Fatalf("RPC allow-list invalid, %s", err)
+		os.Exit(-1)
+	}
 	defer rpcServer.Stop()
 	go func() {
 		if err := rpcServer.Start(); err != nil {
@@ -203,20 +250,31 @@ func main() {
 	defer socketServer.Stop()
 	go func() {
 		if err := socketServer.Start(); err != nil {
-			fmt.Println("Start HttpSocket server failed, %s", err.Error())
+			eladlog.Errorf("Start HttpSocket server failed, %s", err.Error())
+		}
+	}()
+	setMonitorState(cfg.MonitorState)
+	go printSyncState(idChainStore, server)
+
+	// Reload the config file on SIGHUP.
+	reloader := newConfigReloader(cfg, activeNetParams, miner, rpcAllowList)
+	hangup := make(chan os.Signal, 1)
+	ossignal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			reloader.reload()
 		}
 	}()
-	if cfg.MonitorState {
-		go printSyncState(idChainStore.ChainStore, server)
-	}
 
 	<-interrupt.C
 }
 
-func newJsonRpcServer(port uint16, service *sv.HttpServiceExtend) *jsonrpc.Server {
-	s := jsonrpc.NewServer(&jsonrpc.Config{ServePort: port})
+func newJsonRpcServer(port uint16, service *sv.HttpServiceExtend, allowList *rpcAllowList,
+	miner *minerState) *jsonrpc.Server {
+	jsonServer := jsonrpc.NewServer(&jsonrpc.Config{ServePort: port})
+	s := &guardedServer{Server: jsonServer, allowList: allowList}
 
-	s.RegisterAction("setloglevel", service.SetLogLevel, "level")
+	s.RegisterAction("setloglevel", service.SetLogLevel, "level", "subsystem")
 	s.RegisterAction("getblock", service.GetBlockByHash, "blockhash", "verbosity")
 	s.RegisterAction("getcurrentheight", service.GetBlockHeight)
 	s.RegisterAction("getblockhash", service.GetBlockHash, "height")
@@ -236,12 +294,24 @@ func newJsonRpcServer(port uint16, service *sv.HttpServiceExtend) *jsonrpc.Serve
 	s.RegisterAction("getwithdrawtransaction", service.GetWithdrawTransactionByHash, "txid")
 	s.RegisterAction("submitsideauxblock", service.SubmitAuxBlock, "blockhash", "auxpow")
 	s.RegisterAction("createauxblock", service.CreateAuxBlock, "paytoaddress")
-	s.RegisterAction("togglemining", service.ToggleMining, "mining")
+	s.RegisterAction("togglemining", miner.toggleMining(service.HttpService), "mining")
 	s.RegisterAction("discretemining", service.DiscreteMining, "count")
-	s.RegisterAction("getidentificationtxbyidandpath", service.GetIdentificationTxByIdAndPath, "id", "path")
+	s.RegisterAction("getidentificationtxbyidandpath", service.GetIdentificationTxByIdAndPath, "id", "path", "filterexpired", "proof")
+	s.RegisterAction("getidentificationproof", service.GetIdentificationProof, "id", "path")
+	s.RegisterAction("getdiddocument", service.GetDIDDocument, "id")
+	s.RegisterAction("getidentificationcontrollers", service.GetIdentificationControllers, "id")
+	s.RegisterAction("getidentificationrecovery", service.GetIdentificationRecovery, "id")
+	s.RegisterAction("getanchor", service.GetAnchor, "hash")
+	s.RegisterAction("listidentifications", service.ListIdentifications, "cursor", "pathprefix", "haspath", "registeredafter", "limit")
+	s.RegisterAction("getidentificationsbyaddress", service.GetIdentificationsByAddress, "address", "cursor", "limit")
+	s.RegisterAction("getidentificationstats", service.GetIdentificationStats, "days")
+	s.RegisterAction("getidentificationcachestats", service.GetIdentificationCacheStats)
+	s.RegisterAction("getidentificationchanges", service.GetIdentificationChanges, "start", "end", "index", "limit")
+	s.RegisterAction("getidentificationstateroot", service.GetIdentificationStateRoot, "height")
+	s.RegisterAction("getidentificationstateproof", service.GetIdentificationStateProof, "id", "path", "height")
 	s.RegisterAction("listunspent", service.ListUnspent, "addresses")
 
-	return s
+	return jsonServer
 }
 
 func newRESTfulServer(port uint16, service *service.HttpService) *restful.Server {
@@ -306,7 +376,15 @@ func newWebSocketServer(port uint16, service *service.HttpService, config *servi
 	return server
 }
 
-func printSyncState(db *blockchain.ChainStore, server server.Server) {
+func setMonitorState(enabled bool) {
+	var state int32
+	if enabled {
+		state = 1
+	}
+	atomic.StoreInt32(&monitorState, state)
+}
+
+func printSyncState(db *bc.IDChainStore, server server.Server) {
 	logger := elalog.NewBackend(logWriter).Logger("STAT",
 		elalog.LevelInfo)
 
@@ -314,6 +392,10 @@ func printSyncState(db *blockchain.ChainStore, server server.Server) {
 	defer ticker.Stop()
 
 	for range ticker.C {
+		if atomic.LoadInt32(&monitorState) == 0 {
+			continue
+		}
+
 		var buf bytes.Buffer
 		buf.WriteString("-> ")
 		buf.WriteString(strconv.FormatUint(uint64(db.GetHeight()), 10))
@@ -328,6 +410,16 @@ func printSyncState(db *blockchain.ChainStore, server server.Server) {
 			}
 		}
 		buf.WriteString("]")
+		if stats, err := db.GetIdentificationStats(); err == nil {
+			buf.WriteString(" ids ")
+			buf.WriteString(strconv.FormatUint(stats.TotalIDs, 10))
+			buf.WriteString(" paths ")
+			buf.WriteString(strconv.FormatUint(stats.TotalPaths, 10))
+			buf.WriteString(" registrations ")
+			buf.WriteString(strconv.FormatUint(stats.Registrations, 10))
+			buf.WriteString(" revocations ")
+			buf.WriteString(strconv.FormatUint(stats.Revocations, 10))
+		}
 		logger.Info(buf.String())
 	}
 }
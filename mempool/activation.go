@@ -0,0 +1,47 @@
+package mempool
+
+import (
+	"errors"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+)
+
+// txUpgrades returns the upgrades which must be activated for the
+// transaction to be valid.
+func txUpgrades(txn *types.Transaction) []params.Upgrade {
+	var upgrades []params.Upgrade
+	switch txn.Payload.(type) {
+	case *id.PayloadRegisterServiceEndpoint:
+		upgrades = append(upgrades, params.UpgradeServiceEndpoints)
+	case *id.PayloadTransferIdentification:
+		upgrades = append(upgrades, params.UpgradeTransfer)
+	case *id.PayloadSetRecoveryGuardians, *id.PayloadRecoverIdentification,
+		*id.PayloadCancelRecovery:
+		upgrades = append(upgrades, params.UpgradeRecovery)
+	case *id.PayloadRegisterIdentificationBatch:
+		upgrades = append(upgrades, params.UpgradeBatchRegistration)
+	case *id.PayloadAnchor:
+		upgrades = append(upgrades, params.UpgradeAnchor)
+	}
+
+	isRegister := id.IsRegisterIdentificationTx(txn) || id.IsRegisterIdentificationBatchTx(txn)
+	if isRegister && txn.PayloadVersion >= id.RegisterIdentificationVersion1 {
+		upgrades = append(upgrades, params.UpgradeValueExpiry)
+	}
+	return upgrades
+}
+
+// checkActivation checks that the upgrades the transaction depends on apply
+// to the block at the height.
+func checkActivation(activations params.Activations, txn *types.Transaction, height uint32) error {
+	for _, upgrade := range txUpgrades(txn) {
+		if !activations.IsActive(upgrade, height) {
+			return errors.New("[ID checkIdentificationContext] Upgrade " + string(upgrade) +
+				" is not activated.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package mempool
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	bc "github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckActivation(t *testing.T) {
+	const activation = 1000
+	activations := params.Activations{
+		params.UpgradeValueExpiry:       activation,
+		params.UpgradeServiceEndpoints:  activation,
+		params.UpgradeTransfer:          activation,
+		params.UpgradeRecovery:          activation,
+		params.UpgradeBatchRegistration: activation,
+		params.UpgradeAnchor:            activation,
+	}
+
+	legacy := []*types.Transaction{
+		{TxType: types.TransferAsset, Payload: &types.PayloadTransferAsset{}},
+		{TxType: id.RegisterIdentification, PayloadVersion: id.RegisterIdentificationVersion,
+			Payload: &id.PayloadRegisterIdentification{}},
+	}
+	upgraded := []*types.Transaction{
+		{TxType: id.RegisterIdentification, PayloadVersion: id.RegisterIdentificationVersion1,
+			Payload: &id.PayloadRegisterIdentification{}},
+		{TxType: id.RegisterServiceEndpoint, Payload: &id.PayloadRegisterServiceEndpoint{}},
+		{TxType: id.TransferIdentification, Payload: &id.PayloadTransferIdentification{}},
+		{TxType: id.SetRecoveryGuardians, Payload: &id.PayloadSetRecoveryGuardians{}},
+		{TxType: id.RecoverIdentification, Payload: &id.PayloadRecoverIdentification{}},
+		{TxType: id.CancelRecovery, Payload: &id.PayloadCancelRecovery{}},
+		{TxType: id.RegisterIdentificationBatch, Payload: &id.PayloadRegisterIdentificationBatch{}},
+		{TxType: id.Anchor, Payload: &id.PayloadAnchor{}},
+	}
+
+	// The transactions of a block before the activation keep the old rules.
+	for _, txn := range legacy {
+		assert.NoError(t, checkActivation(activations, txn, activation-1))
+		assert.NoError(t, checkActivation(activations, txn, activation))
+	}
+	for _, txn := range upgraded {
+		assert.Error(t, checkActivation(activations, txn, 0))
+		assert.Error(t, checkActivation(activations, txn, activation-1))
+		assert.NoError(t, checkActivation(activations, txn, activation))
+		assert.NoError(t, checkActivation(activations, txn, activation+1))
+	}
+
+	// A batch with expiring values needs both upgrades.
+	batch := &types.Transaction{TxType: id.RegisterIdentificationBatch,
+		PayloadVersion: id.RegisterIdentificationVersion1,
+		Payload:        &id.PayloadRegisterIdentificationBatch{}}
+	delete(activations, params.UpgradeValueExpiry)
+	assert.Error(t, checkActivation(activations, batch, activation))
+}
+
+// newTestValidator returns a validator on a chain store of the regression
+// test network in a temporary folder, whose next block is at height 1, and
+// the function removing it.
+func newTestValidator(t *testing.T, activations params.Activations) (*validator, func()) {
+	dir, err := ioutil.TempDir("", "idvalidator")
+	if err != nil {
+		t.Fatal("create data folder error:", err)
+	}
+	store, err := bc.NewChainStore(params.RegNetGenesisBlock, activations, nil, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("open chain store error:", err)
+	}
+	return &validator{store: store, activations: activations}, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRegisterIdentificationUpgrades(t *testing.T) {
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+	otherHash, _ := common.Uint168FromAddress(other)
+
+	invalidID := &types.Transaction{TxType: id.RegisterIdentification,
+		PayloadVersion: id.RegisterIdentificationVersion,
+		Payload:        &id.PayloadRegisterIdentification{ID: "notanid"}}
+	invalidInfo := &types.Transaction{TxType: id.RegisterIdentification,
+		PayloadVersion: id.RegisterIdentificationVersion,
+		Payload: &id.PayloadRegisterIdentification{ID: testID,
+			Contents: []id.RegisterIdentificationContent{{
+				Path:   "kyc/person/phone",
+				Values: []id.RegisterIdentificationValue{{Info: id.EncryptedInfoPrefix + "garbage"}},
+			}}}}
+	// The first output to an ID is not to the ID being registered.
+	otherOutput := &types.Transaction{TxType: id.RegisterIdentification,
+		PayloadVersion: id.RegisterIdentificationVersion,
+		Payload:        &id.PayloadRegisterIdentification{ID: testID},
+		Outputs:        []*types.Output{{ProgramHash: *otherHash}}}
+
+	for _, upgraded := range []bool{false, true} {
+		// The same transactions in the block before the activation and in
+		// the block at the activation.
+		activation := uint32(2)
+		if upgraded {
+			activation = 1
+		}
+		v, remove := newTestValidator(t, params.Activations{
+			params.UpgradeIDAddress:        activation,
+			params.UpgradeEncryptedInfo:    activation,
+			params.UpgradeControllerSigner: activation,
+			params.UpgradeAnchor:           activation,
+		})
+
+		anchor := &types.Transaction{TxType: id.Anchor, Payload: &id.PayloadAnchor{}}
+		if upgraded {
+			assert.NoError(t, v.checkIdentificationContext(anchor))
+		} else {
+			assert.Error(t, v.checkIdentificationContext(anchor))
+		}
+
+		for _, txn := range []*types.Transaction{invalidID, invalidInfo} {
+			assert.NoError(t, v.checkTransactionPayload(txn))
+			if upgraded {
+				assert.Error(t, v.checkIdentificationContext(txn))
+			} else {
+				assert.NoError(t, v.checkIdentificationContext(txn))
+			}
+		}
+
+		signers, err := v.identificationSigners(otherOutput)
+		if upgraded {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, []common.Uint168{*otherHash}, signers)
+		}
+
+		remove()
+	}
+}
@@ -0,0 +1,509 @@
+package mempool
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func checkRegisterIdentification(version byte, pld *id.PayloadRegisterIdentification) error {
+	if version > id.RegisterIdentificationVersion1 {
+		return errors.New("[ID CheckTransactionPayload] Invalid register identification payload version.")
+	}
+
+	for _, content := range pld.Contents {
+		for _, value := range content.Values {
+			switch value.ExpiryType {
+			case id.ExpiryNone:
+				if value.Expiry != 0 {
+					return errors.New("[ID CheckTransactionPayload] Expiry set without expiry type.")
+				}
+			case id.ExpiryHeight, id.ExpiryTimestamp:
+				if version < id.RegisterIdentificationVersion1 {
+					return errors.New("[ID CheckTransactionPayload] Expiry requires payload version 1.")
+				}
+				if value.Expiry == 0 {
+					return errors.New("[ID CheckTransactionPayload] Invalid expiry.")
+				}
+				if value.ExpiryType == id.ExpiryHeight && value.Expiry > id.MaxExpiryHeight ||
+					value.ExpiryType == id.ExpiryTimestamp && value.Expiry > id.MaxExpiryTimestamp {
+					return errors.New("[ID CheckTransactionPayload] Expiry is too far.")
+				}
+			default:
+				return errors.New("[ID CheckTransactionPayload] Invalid expiry type.")
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRegisterIdentificationUpgrades checks the rules added to the register
+// identification payload by the upgrades: the ID must be the address of an
+// ID, and an Info with the envelope prefix must be a valid envelope. The
+// batch entries follow both rules, a register identification transaction
+// follows those activated at the height of its block.
+func checkRegisterIdentificationUpgrades(pld *id.PayloadRegisterIdentification, idAddress, encryptedInfo bool) error {
+	if idAddress {
+		if err := checkIDAddress(pld.ID); err != nil {
+			return err
+		}
+	}
+
+	if encryptedInfo {
+		for _, content := range pld.Contents {
+			for _, value := range content.Values {
+				if !id.IsEncryptedInfo(value.Info) {
+					continue
+				}
+				if _, err := id.ParseEncryptedInfo(value.Info); err != nil {
+					return errors.New("[ID CheckTransactionPayload] Invalid encrypted info: " + err.Error())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkRegisterIdentificationBatch(version byte, pld *id.PayloadRegisterIdentificationBatch) error {
+	if len(pld.Entries) == 0 {
+		return errors.New("[ID CheckTransactionPayload] Empty identification batch.")
+	}
+	if len(pld.Entries) > id.MaxBatchEntries {
+		return errors.New("[ID CheckTransactionPayload] Too many identification batch entries.")
+	}
+
+	ids := make(map[string]struct{}, len(pld.Entries))
+	for i := range pld.Entries {
+		entry := &pld.Entries[i]
+		if _, ok := ids[entry.ID]; ok {
+			return errors.New("[ID CheckTransactionPayload] Duplicate batch entry of ID " + entry.ID)
+		}
+		ids[entry.ID] = struct{}{}
+
+		if len(entry.Contents) > id.MaxBatchEntryContents {
+			return errors.New("[ID CheckTransactionPayload] Too many paths in batch entry of ID " + entry.ID)
+		}
+		for _, content := range entry.Contents {
+			if len(content.Values) > id.MaxBatchEntryValues {
+				return errors.New("[ID CheckTransactionPayload] Too many values of path " +
+					content.Path + " in batch entry of ID " + entry.ID)
+			}
+		}
+
+		if err := checkRegisterIdentification(version, entry); err != nil {
+			return err
+		}
+		if err := checkRegisterIdentificationUpgrades(entry, true, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkRegisterServiceEndpoint(pld *id.PayloadRegisterServiceEndpoint) error {
+	if err := checkIDAddress(pld.ID); err != nil {
+		return err
+	}
+
+	if len(pld.Endpoints) > id.MaxServiceEndpoints {
+		return errors.New("[ID CheckTransactionPayload] Too many service endpoints.")
+	}
+
+	for _, endpoint := range pld.Endpoints {
+		if err := endpoint.Validate(); err != nil {
+			return errors.New("[ID CheckTransactionPayload] Invalid service endpoint: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func checkTransferIdentification(pld *id.PayloadTransferIdentification) error {
+	if err := checkIDAddress(pld.ID); err != nil {
+		return err
+	}
+
+	return checkIDAddress(pld.NewController)
+}
+
+func checkSetRecoveryGuardians(pld *id.PayloadSetRecoveryGuardians) error {
+	if err := checkIDAddress(pld.ID); err != nil {
+		return err
+	}
+
+	// An empty guardian list disables recovery.
+	if len(pld.Guardians) == 0 {
+		if pld.Threshold != 0 || pld.Delay != 0 {
+			return errors.New("[ID CheckTransactionPayload] Threshold and delay set without guardians.")
+		}
+		return nil
+	}
+
+	if len(pld.Guardians) > id.MaxRecoveryGuardians {
+		return errors.New("[ID CheckTransactionPayload] Too many guardians.")
+	}
+
+	guardians := make(map[string]struct{}, len(pld.Guardians))
+	for _, guardian := range pld.Guardians {
+		if err := checkIDAddress(guardian); err != nil {
+			return err
+		}
+		if guardian == pld.ID {
+			return errors.New("[ID CheckTransactionPayload] ID can not guard itself.")
+		}
+		if _, ok := guardians[guardian]; ok {
+			return errors.New("[ID CheckTransactionPayload] Duplicate guardian " + guardian)
+		}
+		guardians[guardian] = struct{}{}
+	}
+
+	if pld.Threshold == 0 || pld.Threshold > uint32(len(pld.Guardians)) {
+		return errors.New("[ID CheckTransactionPayload] Invalid guardian threshold.")
+	}
+
+	if pld.Delay < id.MinRecoveryDelay {
+		return errors.New("[ID CheckTransactionPayload] Recovery delay is too short.")
+	}
+	if pld.Delay > id.MaxRecoveryDelay {
+		return errors.New("[ID CheckTransactionPayload] Recovery delay is too long.")
+	}
+
+	return nil
+}
+
+func checkRecoverIdentification(pld *id.PayloadRecoverIdentification) error {
+	if err := checkIDAddress(pld.ID); err != nil {
+		return err
+	}
+
+	return checkIDAddress(pld.NewController)
+}
+
+func checkAnchor(pld *id.PayloadAnchor) error {
+	if len(pld.Hashes) == 0 {
+		return errors.New("[ID CheckTransactionPayload] No anchored hash.")
+	}
+	if len(pld.Hashes) > id.MaxAnchorHashes {
+		return errors.New("[ID CheckTransactionPayload] Too many anchored hashes.")
+	}
+	if len(pld.Memo) > id.MaxAnchorMemoSize {
+		return errors.New("[ID CheckTransactionPayload] Anchor memo is too long.")
+	}
+
+	hashes := make(map[common.Uint256]struct{}, len(pld.Hashes))
+	for _, hash := range pld.Hashes {
+		if _, ok := hashes[hash]; ok {
+			return errors.New("[ID CheckTransactionPayload] Duplicate anchored hash.")
+		}
+		hashes[hash] = struct{}{}
+	}
+
+	return nil
+}
+
+// checkIDAddress checks that the address is the address of an ID.
+func checkIDAddress(ID string) error {
+	programHash, err := common.Uint168FromAddress(ID)
+	if err != nil || programHash[0] != common.PrefixRegisterId {
+		return errors.New("[ID CheckTransactionPayload] Invalid ID " + ID)
+	}
+	return nil
+}
+
+func (v *validator) checkIdentificationContext(txn *types.Transaction) error {
+	height := v.store.GetHeight() + 1
+	if err := checkActivation(v.activations, txn, height); err != nil {
+		return err
+	}
+
+	switch pld := txn.Payload.(type) {
+	case *id.PayloadRegisterIdentification:
+		if err := checkRegisterIdentificationUpgrades(pld,
+			v.activations.IsActive(params.UpgradeIDAddress, height),
+			v.activations.IsActive(params.UpgradeEncryptedInfo, height)); err != nil {
+			return err
+		}
+		return v.checkRegisterIdentificationContext(pld)
+	case *id.PayloadRegisterServiceEndpoint:
+		return v.checkSequence(pld.ID, pld.Sequence)
+	case *id.PayloadTransferIdentification:
+		return v.checkTransferIdentificationContext(pld)
+	case *id.PayloadSetRecoveryGuardians:
+		return v.checkSetRecoveryGuardiansContext(pld)
+	case *id.PayloadRecoverIdentification:
+		return v.checkRecoverIdentificationContext(pld)
+	case *id.PayloadCancelRecovery:
+		return v.checkCancelRecoveryContext(pld)
+	case *id.PayloadRegisterIdentificationBatch:
+		for i := range pld.Entries {
+			if err := v.checkRegisterIdentificationContext(&pld.Entries[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) checkRegisterIdentificationContext(pld *id.PayloadRegisterIdentification) error {
+	if pld.Version() == id.RegisterIdentificationVersion {
+		return nil
+	}
+
+	bestHeight := v.store.GetHeight()
+	hash, err := v.store.GetBlockHash(bestHeight)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get best block hash failed:" + err.Error())
+	}
+	header, err := v.store.GetHeader(hash)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get best header failed:" + err.Error())
+	}
+
+	// A value must not be expired already in the block it is registered
+	// in, whose timestamp is not known yet, so the time of the best block
+	// is the closest one.
+	for _, content := range pld.Contents {
+		for _, value := range content.Values {
+			if value.IsExpired(bestHeight+1, header.Timestamp) {
+				return errors.New("[ID checkIdentificationContext] Value of path " +
+					content.Path + " is already expired.")
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkSequence checks that the payload applies to the current sequence number
+// of the ID, so a payload signed for an earlier state of the ID is not
+// replayed.
+func (v *validator) checkSequence(ID string, sequence uint32) error {
+	current, err := v.store.GetIdentificationSequence(ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get sequence failed:" + err.Error())
+	}
+	if sequence != current {
+		return errors.New("[ID checkIdentificationContext] Sequence " +
+			strconv.FormatUint(uint64(sequence), 10) + " of ID " + ID +
+			" is not the current sequence " + strconv.FormatUint(uint64(current), 10))
+	}
+
+	return nil
+}
+
+func (v *validator) checkTransferIdentificationContext(pld *id.PayloadTransferIdentification) error {
+	if err := v.checkSequence(pld.ID, pld.Sequence); err != nil {
+		return err
+	}
+
+	controller, err := v.store.GetIdentificationController(pld.ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get controller failed:" + err.Error())
+	}
+	if controller == pld.NewController {
+		return errors.New("[ID checkIdentificationContext] ID " + pld.ID +
+			" is already controlled by " + pld.NewController)
+	}
+
+	return nil
+}
+
+func (v *validator) checkSetRecoveryGuardiansContext(pld *id.PayloadSetRecoveryGuardians) error {
+	if err := v.checkSequence(pld.ID, pld.Sequence); err != nil {
+		return err
+	}
+
+	recovery, err := v.store.GetPendingRecovery(pld.ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get pending recovery failed:" + err.Error())
+	}
+	if recovery != nil {
+		return errors.New("[ID checkIdentificationContext] Can not change guardians of ID " +
+			pld.ID + " during a recovery.")
+	}
+
+	return nil
+}
+
+func (v *validator) checkRecoverIdentificationContext(pld *id.PayloadRecoverIdentification) error {
+	if err := v.checkSequence(pld.ID, pld.Sequence); err != nil {
+		return err
+	}
+
+	guardians, err := v.store.GetRecoveryGuardians(pld.ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get guardians failed:" + err.Error())
+	}
+	if guardians == nil {
+		return errors.New("[ID checkIdentificationContext] ID " + pld.ID + " has no guardians.")
+	}
+
+	recovery, err := v.store.GetPendingRecovery(pld.ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get pending recovery failed:" + err.Error())
+	}
+	if recovery != nil {
+		return errors.New("[ID checkIdentificationContext] ID " + pld.ID + " is already in recovery.")
+	}
+
+	controller, err := v.store.GetIdentificationController(pld.ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get controller failed:" + err.Error())
+	}
+	if controller == pld.NewController {
+		return errors.New("[ID checkIdentificationContext] ID " + pld.ID +
+			" is already controlled by " + pld.NewController)
+	}
+
+	return nil
+}
+
+func (v *validator) checkCancelRecoveryContext(pld *id.PayloadCancelRecovery) error {
+	if err := v.checkSequence(pld.ID, pld.Sequence); err != nil {
+		return err
+	}
+
+	recovery, err := v.store.GetPendingRecovery(pld.ID)
+	if err != nil {
+		return errors.New("[ID checkIdentificationContext] Get pending recovery failed:" + err.Error())
+	}
+	if recovery == nil {
+		return errors.New("[ID checkIdentificationContext] ID " + pld.ID + " is not in recovery.")
+	}
+
+	return nil
+}
+
+// guardianSigners returns the controllers of the guardians of the ID which
+// the transaction has an output to, there must be at least the threshold of
+// them.
+func (v *validator) guardianSigners(txn *types.Transaction, ID string) ([]string, error) {
+	guardians, err := v.store.GetRecoveryGuardians(ID)
+	if err != nil {
+		return nil, err
+	}
+	if guardians == nil {
+		return nil, errors.New("ID " + ID + " has no guardians")
+	}
+
+	// Guardians sharing a controller count once, as they share a signature.
+	var signers []string
+	controllers := make(map[string]struct{})
+	for _, guardian := range guardians.Guardians {
+		controller, err := v.store.GetIdentificationController(guardian)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := controllers[controller]; ok {
+			continue
+		}
+		controllers[controller] = struct{}{}
+
+		programHash, err := common.Uint168FromAddress(controller)
+		if err != nil {
+			return nil, errors.New("invalid guardian controller " + controller)
+		}
+		if hasOutput(txn, *programHash) {
+			signers = append(signers, controller)
+		}
+	}
+
+	if uint32(len(signers)) < guardians.Threshold {
+		return nil, errors.New("not enough guardians to recover ID " + ID)
+	}
+
+	return signers, nil
+}
+
+// identificationSigners returns the program hashes of the IDs which must sign
+// the ID transaction, the transaction must have an output to each of them.
+func (v *validator) identificationSigners(txn *types.Transaction) ([]common.Uint168, error) {
+	// The ID whose controller must sign the transaction.
+	var controlled string
+	var signers []string
+	switch pld := txn.Payload.(type) {
+	case *id.PayloadRegisterIdentification:
+		if !v.activations.IsActive(params.UpgradeControllerSigner, v.store.GetHeight()+1) {
+			return firstIDOutput(txn), nil
+		}
+		controlled = pld.ID
+	case *id.PayloadRegisterServiceEndpoint:
+		controlled = pld.ID
+	case *id.PayloadTransferIdentification:
+		controlled = pld.ID
+		signers = append(signers, pld.NewController)
+	case *id.PayloadSetRecoveryGuardians:
+		controlled = pld.ID
+	case *id.PayloadCancelRecovery:
+		controlled = pld.ID
+	case *id.PayloadRecoverIdentification:
+		guardians, err := v.guardianSigners(txn, pld.ID)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, guardians...)
+	case *id.PayloadRegisterIdentificationBatch:
+		// Entries are signed by the IDs themselves, so a batch can only
+		// register IDs which have not been handed over to another controller.
+		for _, entry := range pld.Entries {
+			controller, err := v.store.GetIdentificationController(entry.ID)
+			if err != nil {
+				return nil, err
+			}
+			if controller != entry.ID {
+				return nil, errors.New("ID " + entry.ID + " is controlled by " + controller)
+			}
+			signers = append(signers, entry.ID)
+		}
+	}
+
+	if controlled != "" {
+		controller, err := v.store.GetIdentificationController(controlled)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, controller)
+	}
+
+	hashes := make([]common.Uint168, 0, len(signers))
+	for _, signer := range signers {
+		programHash, err := common.Uint168FromAddress(signer)
+		if err != nil {
+			return nil, errors.New("invalid signer " + signer)
+		}
+		if !hasOutput(txn, *programHash) {
+			return nil, errors.New("no output to signer " + signer)
+		}
+		hashes = append(hashes, *programHash)
+	}
+
+	return hashes, nil
+}
+
+// firstIDOutput returns the program hash of the first output to an ID, the
+// signer of a register identification transaction before the controllers.
+func firstIDOutput(txn *types.Transaction) []common.Uint168 {
+	for _, output := range txn.Outputs {
+		if output.ProgramHash[0] == common.PrefixRegisterId {
+			return []common.Uint168{output.ProgramHash}
+		}
+	}
+	return nil
+}
+
+func hasOutput(txn *types.Transaction, programHash common.Uint168) bool {
+	for _, output := range txn.Outputs {
+		if output.ProgramHash.IsEqual(programHash) {
+			return true
+		}
+	}
+	return false
+}
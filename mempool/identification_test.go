@@ -0,0 +1,74 @@
+package mempool
+
+import (
+	"math"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+
+func expiringPayload(expiryType byte, expiry uint32) *id.PayloadRegisterIdentification {
+	return &id.PayloadRegisterIdentification{
+		ID: testID,
+		Contents: []id.RegisterIdentificationContent{{
+			Path: "kyc/person/phone",
+			Values: []id.RegisterIdentificationValue{{
+				ExpiryType: expiryType,
+				Expiry:     expiry,
+			}},
+		}},
+	}
+}
+
+func TestCheckRegisterIdentificationExpiry(t *testing.T) {
+	version := byte(id.RegisterIdentificationVersion1)
+	assert.NoError(t, checkRegisterIdentification(version, expiringPayload(id.ExpiryNone, 0)))
+	assert.NoError(t, checkRegisterIdentification(version, expiringPayload(id.ExpiryHeight, 1)))
+	assert.NoError(t, checkRegisterIdentification(version,
+		expiringPayload(id.ExpiryHeight, id.MaxExpiryHeight)))
+	assert.NoError(t, checkRegisterIdentification(version,
+		expiringPayload(id.ExpiryTimestamp, id.MaxExpiryTimestamp)))
+
+	assert.Error(t, checkRegisterIdentification(version, expiringPayload(id.ExpiryNone, 1)))
+	assert.Error(t, checkRegisterIdentification(version, expiringPayload(id.ExpiryHeight, 0)))
+	assert.Error(t, checkRegisterIdentification(version,
+		expiringPayload(id.ExpiryHeight, id.MaxExpiryHeight+1)))
+	assert.Error(t, checkRegisterIdentification(version,
+		expiringPayload(id.ExpiryTimestamp, id.MaxExpiryTimestamp+1)))
+	assert.Error(t, checkRegisterIdentification(version, expiringPayload(0x03, 1)))
+
+	// An expiry needs the payload version 1.
+	assert.Error(t, checkRegisterIdentification(id.RegisterIdentificationVersion,
+		expiringPayload(id.ExpiryHeight, 1)))
+}
+
+func TestCheckRegisterIdentificationContextExpiry(t *testing.T) {
+	v, remove := newTestValidator(t, params.RegNetActivations)
+	defer remove()
+
+	// The transaction is included in the block on top of the best block,
+	// a value expiring at its height is already expired.
+	assert.Error(t, v.checkRegisterIdentificationContext(expiringPayload(id.ExpiryHeight, 1)))
+	assert.NoError(t, v.checkRegisterIdentificationContext(expiringPayload(id.ExpiryHeight, 2)))
+}
+
+func TestCheckSetRecoveryGuardiansDelay(t *testing.T) {
+	const guardian = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+	guardians := func(delay uint32) *id.PayloadSetRecoveryGuardians {
+		return &id.PayloadSetRecoveryGuardians{ID: testID, Guardians: []string{guardian},
+			Threshold: 1, Delay: delay}
+	}
+
+	assert.NoError(t, checkSetRecoveryGuardians(guardians(id.MinRecoveryDelay)))
+	assert.NoError(t, checkSetRecoveryGuardians(guardians(id.MaxRecoveryDelay)))
+	assert.Error(t, checkSetRecoveryGuardians(guardians(id.MinRecoveryDelay-1)))
+
+	// A delay wrapping the effective height of the recovery around.
+	assert.Error(t, checkSetRecoveryGuardians(guardians(id.MaxRecoveryDelay+1)))
+	assert.Error(t, checkSetRecoveryGuardians(guardians(math.MaxUint32)))
+}
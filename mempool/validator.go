@@ -4,6 +4,8 @@ import (
 	"errors"
 	"math"
 
+	bc "github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
 	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
 
 	"github.com/elastos/Elastos.ELA.SideChain/mempool"
@@ -12,24 +14,52 @@ import (
 	"github.com/elastos/Elastos.ELA.Utility/common"
 )
 
+// CheckIdentificationContext is the name of the identification context check
+// registered on the side chain validator.
+const CheckIdentificationContext = "checkidentificationcontext"
+
 type validator struct {
 	*mempool.Validator
 
 	systemAssetID common.Uint256
-	foundation    common.Uint168
 	spvService    *spv.Service
+	store         *bc.IDChainStore
+	activations   params.Activations
+
+	// skipCheckpointScripts skips the scripts of the transactions in the
+	// blocks up to the last checkpoint, it is only set on the validator of
+	// the blocks.
+	skipCheckpointScripts bool
+}
+
+// NewValidator returns the validator of the transactions entering the
+// mempool.
+func NewValidator(cfg *mempool.Config, store *bc.IDChainStore) *mempool.Validator {
+	return newValidator(cfg, store, false)
+}
+
+// NewBlockValidator returns the validator of the transactions of the blocks,
+// which skips the scripts of the transactions in the blocks up to the last
+// checkpoint when skipCheckpointScripts is set.
+func NewBlockValidator(cfg *mempool.Config, store *bc.IDChainStore,
+	skipCheckpointScripts bool) *mempool.Validator {
+	return newValidator(cfg, store, skipCheckpointScripts)
 }
 
-func NewValidator(cfg *mempool.Config) *mempool.Validator {
+func newValidator(cfg *mempool.Config, store *bc.IDChainStore,
+	skipCheckpointScripts bool) *mempool.Validator {
 	var val validator
 	val.Validator = mempool.NewValidator(cfg)
 	val.systemAssetID = cfg.ChainParams.ElaAssetId
-	val.foundation = cfg.ChainParams.Foundation
 	val.spvService = cfg.SpvService
+	val.store = store
+	val.activations = params.GetActivations(cfg.ChainParams)
+	val.skipCheckpointScripts = skipCheckpointScripts
 
 	val.RegisterSanityFunc(mempool.FuncNames.CheckTransactionOutput, val.checkTransactionOutput)
 	val.RegisterSanityFunc(mempool.FuncNames.CheckTransactionPayload, val.checkTransactionPayload)
 	val.RegisterContextFunc(mempool.FuncNames.CheckTransactionSignature, val.checkTransactionSignature)
+	val.RegisterContextFunc(CheckIdentificationContext, val.checkIdentificationContext)
 	return val.Validator
 }
 
@@ -48,6 +78,37 @@ func (v *validator) checkTransactionPayload(txn *types.Transaction) error {
 	case *types.PayloadRechargeToSideChain:
 	case *types.PayloadTransferCrossChainAsset:
 	case *id.PayloadRegisterIdentification:
+		if err := checkRegisterIdentification(txn.PayloadVersion, pld); err != nil {
+			return err
+		}
+	case *id.PayloadRegisterServiceEndpoint:
+		if err := checkRegisterServiceEndpoint(pld); err != nil {
+			return err
+		}
+	case *id.PayloadTransferIdentification:
+		if err := checkTransferIdentification(pld); err != nil {
+			return err
+		}
+	case *id.PayloadSetRecoveryGuardians:
+		if err := checkSetRecoveryGuardians(pld); err != nil {
+			return err
+		}
+	case *id.PayloadRecoverIdentification:
+		if err := checkRecoverIdentification(pld); err != nil {
+			return err
+		}
+	case *id.PayloadCancelRecovery:
+		if err := checkIDAddress(pld.ID); err != nil {
+			return err
+		}
+	case *id.PayloadRegisterIdentificationBatch:
+		if err := checkRegisterIdentificationBatch(txn.PayloadVersion, pld); err != nil {
+			return err
+		}
+	case *id.PayloadAnchor:
+		if err := checkAnchor(pld); err != nil {
+			return err
+		}
 	default:
 		return errors.New("[ID CheckTransactionPayload] [txValidator],invalidate transaction payload type.")
 	}
@@ -64,19 +125,12 @@ func (v *validator) checkTransactionOutput(txn *types.Transaction) error {
 			return errors.New("[checkTransactionOutput] coinbase output is not enough, at least 2")
 		}
 
-		var totalReward = common.Fixed64(0)
-		var foundationReward = common.Fixed64(0)
+		// The share of the foundation is checked with the block, against
+		// the reward schedule at its height.
 		for _, output := range txn.Outputs {
 			if !output.AssetID.IsEqual(v.systemAssetID) {
 				return errors.New("[checkTransactionOutput] asset ID in coinbase is invalid")
 			}
-			totalReward += output.Value
-			if output.ProgramHash.IsEqual(v.foundation) {
-				foundationReward += output.Value
-			}
-		}
-		if common.Fixed64(foundationReward) < common.Fixed64(float64(totalReward)*0.3) {
-			return errors.New("[checkTransactionOutput] Reward to foundation in coinbase < 30%")
 		}
 
 		return nil
@@ -121,19 +175,25 @@ func (v *validator) checkTransactionSignature(txn *types.Transaction) error {
 		return nil
 	}
 
+	// The blocks up to the last checkpoint are known to be valid, a fork
+	// of them is rejected at the checkpoint.
+	if v.skipCheckpointScripts && v.store.IsBelowLastCheckpoint(v.store.GetHeight()+1) {
+		return nil
+	}
+
 	hashes, err := v.TxProgramHashes(txn)
 	if err != nil {
 		return errors.New("[ID checkTransactionSignature] Get program hashes error:" + err.Error())
 	}
 
-	// Add ID program hash to hashes
-	if id.IsRegisterIdentificationTx(txn) {
-		for _, output := range txn.Outputs {
-			if output.ProgramHash[0] == common.PrefixRegisterId {
-				hashes = append(hashes, output.ProgramHash)
-				break
-			}
+	// Add the program hashes of the controllers signing the ID transaction
+	// to hashes
+	if id.IsIdentificationTx(txn) {
+		signers, err := v.identificationSigners(txn)
+		if err != nil {
+			return errors.New("[ID checkTransactionSignature] " + err.Error())
 		}
+		hashes = append(hashes, signers...)
 	}
 
 	// Sort first
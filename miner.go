@@ -0,0 +1,145 @@
+package main
+
+import (
+	"sync"
+
+	bc "github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
+
+	"github.com/elastos/Elastos.ELA.SideChain/pow"
+	"github.com/elastos/Elastos.ELA.SideChain/service"
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+	"github.com/elastos/Elastos.ELA.Utility/http/util"
+)
+
+// minerState holds the mining settings which change at runtime, by a config
+// reload or the togglemining RPC, while the pow service generates blocks.
+// The pow service reads the settings from the config given to its generate
+// functions, so they are given a copy holding the current ones.
+type minerState struct {
+	sync.RWMutex
+	addr   string
+	info   string
+	mining bool
+
+	powService *pow.Service
+	store      *bc.IDChainStore
+}
+
+func newMinerState(cfg *appConfig, store *bc.IDChainStore) *minerState {
+	return &minerState{addr: cfg.MinerAddr, info: cfg.MinerInfo, mining: cfg.Mining, store: store}
+}
+
+// config returns a copy of the pow config with the current miner settings.
+func (m *minerState) config(cfg *pow.Config) *pow.Config {
+	m.RLock()
+	defer m.RUnlock()
+
+	current := *cfg
+	current.MinerAddr = m.addr
+	current.MinerInfo = m.info
+	return &current
+}
+
+func (m *minerState) generateBlock(cfg *pow.Config) (*types.Block, error) {
+	return pow.GenerateBlock(m.config(cfg))
+}
+
+func (m *minerState) createCoinBaseTx(cfg *pow.Config, nextBlockHeight uint32,
+	addr string) (*types.Transaction, error) {
+	return pow.CreateCoinBaseTx(m.config(cfg), nextBlockHeight, addr)
+}
+
+// generateBlockTransactions fills the block with the transactions of the
+// pool, less the ones the block validator rejects for the sequence number of
+// their ID. The pool checks every transaction against the stored sequence
+// number only, so it takes several transactions of an ID applying to the same
+// one, and keeps them once one of them is in a block.
+func (m *minerState) generateBlockTransactions(cfg *pow.Config, msgBlock *types.Block,
+	coinBaseTx *types.Transaction) {
+	pow.GenerateBlockTransactions(cfg, msgBlock, coinBaseTx)
+
+	var fee common.Fixed64
+	taken := make(map[string]struct{})
+	transactions := make([]*types.Transaction, 0, len(msgBlock.Transactions))
+	for _, txn := range msgBlock.Transactions {
+		ID, sequence, ok := bc.TransactionSequence(txn)
+		if !ok {
+			transactions = append(transactions, txn)
+			continue
+		}
+		current, err := m.store.GetIdentificationSequence(ID)
+		_, conflict := taken[ID]
+		if err != nil || sequence != current || conflict {
+			fee += cfg.TxFeeHelper.GetTxFee(txn)
+			continue
+		}
+		taken[ID] = struct{}{}
+		transactions = append(transactions, txn)
+	}
+	msgBlock.Transactions = transactions
+
+	deductCoinbaseReward(coinBaseTx, cfg.ChainParams.Foundation, fee)
+}
+
+// deductCoinbaseReward takes the fee of the transactions left out of the
+// block off the rewards of the coinbase, the ones of the miner first, so the
+// share of the foundation does not shrink.
+func deductCoinbaseReward(coinBaseTx *types.Transaction, foundation common.Uint168, fee common.Fixed64) {
+	for _, toFoundation := range []bool{false, true} {
+		for _, output := range coinBaseTx.Outputs {
+			if fee == 0 {
+				return
+			}
+			if output.ProgramHash.IsEqual(foundation) != toFoundation {
+				continue
+			}
+			deducted := fee
+			if output.Value < deducted {
+				deducted = output.Value
+			}
+			output.Value -= deducted
+			fee -= deducted
+		}
+	}
+}
+
+// setMiner sets the pay to address and the miner info of the next blocks.
+func (m *minerState) setMiner(addr, info string) {
+	m.Lock()
+	m.addr, m.info = addr, info
+	m.Unlock()
+}
+
+// setMining starts or halts the pow service, and returns whether it was not
+// in that state already.
+func (m *minerState) setMining(mining bool) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.mining == mining {
+		return false
+	}
+	if mining {
+		go m.powService.Start()
+	} else {
+		m.powService.Halt()
+	}
+	m.mining = mining
+	return true
+}
+
+// toggleMining handles the togglemining RPC, so the state of the pow
+// service is known to the config reload.
+func (m *minerState) toggleMining(s *service.HttpService) util.Handler {
+	return func(param util.Params) (interface{}, error) {
+		m.Lock()
+		defer m.Unlock()
+
+		result, err := s.ToggleMining(param)
+		if err == nil {
+			m.mining, _ = param.Bool("mining")
+		}
+		return result, err
+	}
+}
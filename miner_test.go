@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestDeductCoinbaseReward(t *testing.T) {
+	foundation := common.Uint168{0x12, 1}
+	miner := common.Uint168{0x21, 2}
+	coinbase := func(foundationReward, minerReward common.Fixed64) *types.Transaction {
+		return &types.Transaction{Outputs: []*types.Output{
+			{ProgramHash: foundation, Value: foundationReward},
+			{ProgramHash: miner, Value: minerReward},
+		}}
+	}
+
+	// The fee of the transactions left out comes off the reward of the
+	// miner first.
+	tx := coinbase(30, 70)
+	deductCoinbaseReward(tx, foundation, 50)
+	if tx.Outputs[0].Value != 30 || tx.Outputs[1].Value != 20 {
+		t.Errorf("rewards %d, %d", tx.Outputs[0].Value, tx.Outputs[1].Value)
+	}
+
+	tx = coinbase(30, 70)
+	deductCoinbaseReward(tx, foundation, 80)
+	if tx.Outputs[0].Value != 20 || tx.Outputs[1].Value != 0 {
+		t.Errorf("rewards %d, %d", tx.Outputs[0].Value, tx.Outputs[1].Value)
+	}
+}
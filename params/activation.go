@@ -0,0 +1,95 @@
+package params
+
+import (
+	"github.com/elastos/Elastos.ELA.SideChain/config"
+)
+
+// Upgrade names a change of the identification consensus rules. A block
+// follows the rules of the upgrades activated at its height.
+type Upgrade string
+
+const (
+	// UpgradeValueExpiry allows the register identification payload
+	// version 1, which carries expiring values.
+	UpgradeValueExpiry Upgrade = "valueexpiry"
+
+	// UpgradeServiceEndpoints allows the register service endpoint
+	// transactions.
+	UpgradeServiceEndpoints Upgrade = "serviceendpoints"
+
+	// UpgradeTransfer allows the transfer identification transactions.
+	UpgradeTransfer Upgrade = "transfer"
+
+	// UpgradeRecovery allows the set recovery guardians, recover
+	// identification and cancel recovery transactions.
+	UpgradeRecovery Upgrade = "recovery"
+
+	// UpgradeBatchRegistration allows the register identification batch
+	// transactions.
+	UpgradeBatchRegistration Upgrade = "batchregistration"
+
+	// UpgradeAnchor allows the anchor transactions.
+	UpgradeAnchor Upgrade = "anchor"
+
+	// UpgradeIDAddress requires the ID of a register identification
+	// transaction to be the address of an ID.
+	UpgradeIDAddress Upgrade = "idaddress"
+
+	// UpgradeEncryptedInfo requires the Info of a value of a register
+	// identification transaction to be a valid envelope when it starts with
+	// the envelope prefix.
+	UpgradeEncryptedInfo Upgrade = "encryptedinfo"
+
+	// UpgradeControllerSigner requires a register identification
+	// transaction to be signed by the controller of its ID, instead of the ID
+	// of its first output to an ID.
+	UpgradeControllerSigner Upgrade = "controllersigner"
+)
+
+// Activations maps an upgrade to the height of the first block it applies
+// to. An upgrade missing from the schedule is not activated.
+type Activations map[Upgrade]uint32
+
+// IsActive returns whether the upgrade applies to the block at the height.
+func (a Activations) IsActive(upgrade Upgrade, height uint32) bool {
+	activation, ok := a[upgrade]
+	return ok && height >= activation
+}
+
+// MainNetActivations is the upgrade schedule of the main network, no
+// upgrade is scheduled yet.
+var MainNetActivations = Activations{}
+
+// TestNetActivations is the upgrade schedule of the test network, no upgrade
+// is scheduled yet.
+var TestNetActivations = Activations{}
+
+// RegNetActivations is the upgrade schedule of the regression test network,
+// every upgrade applies from the genesis block.
+var RegNetActivations = Activations{
+	UpgradeValueExpiry:       0,
+	UpgradeServiceEndpoints:  0,
+	UpgradeTransfer:          0,
+	UpgradeRecovery:          0,
+	UpgradeBatchRegistration: 0,
+	UpgradeAnchor:            0,
+	UpgradeIDAddress:         0,
+	UpgradeEncryptedInfo:     0,
+	UpgradeControllerSigner:  0,
+}
+
+// networkActivations are the upgrade schedules by network name.
+var networkActivations = map[string]Activations{
+	MainNetParams.Name: MainNetActivations,
+	TestNetParams.Name: TestNetActivations,
+	RegNetParams.Name:  RegNetActivations,
+}
+
+// GetActivations returns the upgrade schedule of the network of the chain
+// parameters, an unknown network has no upgrade activated.
+func GetActivations(chainParams *config.Params) Activations {
+	if activations, ok := networkActivations[chainParams.Name]; ok {
+		return activations
+	}
+	return Activations{}
+}
@@ -0,0 +1,72 @@
+package params
+
+import (
+	"github.com/elastos/Elastos.ELA.SideChain/config"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// Checkpoint is a block known to be in the best chain of a network.
+type Checkpoint struct {
+	Height uint32
+	Hash   common.Uint256
+}
+
+// Checkpoints is a list of checkpoints in ascending height order.
+type Checkpoints []Checkpoint
+
+// Get returns the checkpoint at the height, if any.
+func (c Checkpoints) Get(height uint32) (*Checkpoint, bool) {
+	for i := range c {
+		if c[i].Height == height {
+			return &c[i], true
+		}
+	}
+	return nil, false
+}
+
+// Last returns the highest checkpoint, or nil if there is none.
+func (c Checkpoints) Last() *Checkpoint {
+	if len(c) == 0 {
+		return nil
+	}
+	return &c[len(c)-1]
+}
+
+// Reached returns the highest checkpoint not above the height, or nil if
+// there is none.
+func (c Checkpoints) Reached(height uint32) *Checkpoint {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].Height <= height {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// IsBelowLast returns whether the height is not above the highest
+// checkpoint.
+func (c Checkpoints) IsBelowLast(height uint32) bool {
+	last := c.Last()
+	return last != nil && height <= last.Height
+}
+
+// MainNetCheckpoints are the checkpoints of the main network. Add a block to
+// the list once it is buried deep enough in the chain, with the hash given
+// by a synced node.
+var MainNetCheckpoints = Checkpoints{}
+
+// TestNetCheckpoints are the checkpoints of the test network.
+var TestNetCheckpoints = Checkpoints{}
+
+// networkCheckpoints are the checkpoints by network name, the regression
+// test network has none.
+var networkCheckpoints = map[string]Checkpoints{
+	MainNetParams.Name: MainNetCheckpoints,
+	TestNetParams.Name: TestNetCheckpoints,
+}
+
+// GetCheckpoints returns the checkpoints of the network of the chain
+// parameters.
+func GetCheckpoints(chainParams *config.Params) Checkpoints {
+	return networkCheckpoints[chainParams.Name]
+}
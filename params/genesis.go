@@ -1,12 +1,14 @@
 package params
 
 import (
+	"math"
 	"time"
 
 	"github.com/elastos/Elastos.ELA.SideChain/auxpow"
 	"github.com/elastos/Elastos.ELA.SideChain/types"
 
 	"github.com/elastos/Elastos.ELA.Utility/common"
+	"github.com/elastos/Elastos.ELA.Utility/crypto"
 	"github.com/elastos/Elastos.ELA/core"
 )
 
@@ -38,11 +40,11 @@ var (
 		Version:    types.BlockVersion,
 		Previous:   common.EmptyHash,
 		MerkleRoot: ElaAssetId,
-		Timestamp:  uint32(time.Unix(time.Date(2018, time.June, 30,
+		Timestamp: uint32(time.Unix(time.Date(2018, time.June, 30,
 			12, 0, 0, 0, time.UTC).Unix(), 0).Unix()),
-		Bits:       0x1d03ffff,
-		Nonce:      types.GenesisNonce,
-		Height:     uint32(0),
+		Bits:   0x1d03ffff,
+		Nonce:  types.GenesisNonce,
+		Height: uint32(0),
 		SideAuxPow: auxpow.SideAuxPow{
 			SideAuxBlockTx: core.Transaction{
 				TxType:         core.SideChainPow,
@@ -58,3 +60,57 @@ var (
 		Transactions: []*types.Transaction{&elaAsset},
 	}
 )
+
+// Premine is an output of the genesis block of a private network.
+type Premine struct {
+	ProgramHash common.Uint168
+	Value       common.Fixed64
+}
+
+// GenerateGenesisBlock returns a genesis block of a private network, with the
+// ELA asset registration and, when premines are given, a coinbase transaction
+// paying them. The block only depends on its arguments, so every node given
+// the same ones generates the same genesis block.
+func GenerateGenesisBlock(bits uint32, timestamp uint32, premines []Premine) (*types.Block, error) {
+	transactions := []*types.Transaction{&elaAsset}
+	if len(premines) > 0 {
+		coinbase := &types.Transaction{
+			TxType:         types.CoinBase,
+			PayloadVersion: 0,
+			Payload:        &types.PayloadCoinBase{CoinbaseData: []byte("premine")},
+			Attributes:     []*types.Attribute{},
+			Inputs: []*types.Input{{
+				Previous: types.OutPoint{TxID: common.EmptyHash, Index: math.MaxUint16},
+				Sequence: math.MaxUint32,
+			}},
+			Programs: []*types.Program{},
+		}
+		for _, premine := range premines {
+			coinbase.Outputs = append(coinbase.Outputs, &types.Output{
+				AssetID:     ElaAssetId,
+				Value:       premine.Value,
+				ProgramHash: premine.ProgramHash,
+			})
+		}
+		transactions = append(transactions, coinbase)
+	}
+
+	hashes := make([]common.Uint256, 0, len(transactions))
+	for _, txn := range transactions {
+		hashes = append(hashes, txn.Hash())
+	}
+	merkleRoot, err := crypto.ComputeRoot(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	header := genesisHeader
+	header.MerkleRoot = merkleRoot
+	header.Timestamp = timestamp
+	header.Bits = bits
+
+	return &types.Block{
+		Header:       header,
+		Transactions: transactions,
+	}, nil
+}
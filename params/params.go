@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/elastos/Elastos.ELA.SideChain/config"
+	"github.com/elastos/Elastos.ELA.SideChain/types"
 )
 
 // These variables are the chain proof-of-work limit parameters for each default
@@ -32,6 +33,22 @@ var (
 		0xde, 0x30, 0x79, 0xe3, 0xf8, 0xde, 0x91,
 		0xf4, 0x9c, 0xaa, 0x97, 0x01, 0x5c, 0x9e,
 	}
+
+	// regNetFoundation is the foundation of the regression test network,
+	// the program hash of a standard script no one has the key of.
+	regNetFoundation = common.Uint168{
+		0x12, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+	}
+
+	// regNetPowLimitBits is the compact form of a target any hash meets.
+	regNetPowLimitBits uint32 = 0x207fffff
+
+	// regNetGenesisTimestamp is the timestamp of the genesis blocks generated
+	// for the regression test network.
+	regNetGenesisTimestamp = uint32(time.Date(2018, time.June, 30,
+		12, 0, 0, 0, time.UTC).Unix())
 )
 
 // MainNetSpvParams defines the network parameters for the main network SPV.
@@ -117,7 +134,74 @@ var TestNetParams = config.Params{
 		"did-testnet-005.elastos.org",
 	},
 
-	Foundation: testNetFoundation,
+	Foundation:         testNetFoundation,
+	ElaAssetId:         ElaAssetId,
+	GenesisBlock:       GenesisBlock,
+	PowLimit:           powLimit,
+	PowLimitBits:       0x1f0008ff,
+	TargetTimespan:     24 * time.Hour,  // 24 hours
+	TargetTimePerBlock: 2 * time.Minute, // 2 minute
+	AdjustmentFactor:   4,               // 25% less, 400% more
+	CoinbaseMaturity:   100,
+	MinTransactionFee:  100,
+	ExchangeRate:       1,
+	MinCrossChainTxFee: 10000,
 
 	SpvParams: TestNetSpvParams,
-}
\ No newline at end of file
+}
+
+// RegNetSpvParams defines the network parameters for the SPV of the
+// regression test network. There are no seeds, the main chain nodes of the
+// private network are given by the SpvSeedList of the config file. The
+// foundation is the one of the test network on purpose: a private main chain
+// started with the default parameters of the main chain node uses it, and
+// MainChainFoundationAddress of the config file sets another one.
+var RegNetSpvParams = config.SpvParams{
+	Magic:      2018201,
+	Foundation: "8ZNizBf4KhhPjeJRGpox6rPcHE5Np6tFx3",
+
+	SeedList: []string{},
+
+	DefaultPort: 22866,
+}
+
+// RegNetParams defines the network parameters for the regression test
+// network, a private network for tests where blocks are mined instantly.
+// There are no seeds, so a node never connects to a public node unless given
+// by the config file.
+var RegNetParams = config.Params{
+	Name:        "regnet",
+	Magic:       20180021,
+	DefaultPort: 22608,
+
+	SeedList: []string{},
+
+	Foundation:         regNetFoundation,
+	ElaAssetId:         ElaAssetId,
+	GenesisBlock:       RegNetGenesisBlock,
+	PowLimit:           powLimit,
+	PowLimitBits:       regNetPowLimitBits,
+	TargetTimespan:     10 * time.Second,
+	TargetTimePerBlock: time.Second,
+	AdjustmentFactor:   4, // 25% less, 400% more
+	CoinbaseMaturity:   100,
+	MinTransactionFee:  100,
+	ExchangeRate:       1,
+	MinCrossChainTxFee: 10000,
+
+	SpvParams: RegNetSpvParams,
+}
+
+// RegNetGenesisBlock is the genesis block of the regression test network
+// without premine.
+var RegNetGenesisBlock = GenerateRegNetGenesisBlock(nil)
+
+// GenerateRegNetGenesisBlock returns the genesis block of the regression test
+// network paying the premines.
+func GenerateRegNetGenesisBlock(premines []Premine) *types.Block {
+	block, err := GenerateGenesisBlock(regNetPowLimitBits, regNetGenesisTimestamp, premines)
+	if err != nil {
+		panic(err)
+	}
+	return block
+}
@@ -36,3 +36,86 @@ func TestParams(t *testing.T) {
 	assert.Equal(t, "8NRxtbMKScEWzW8gmPDGUZ8LSzm688nkZZ", addr)
 	t.Log(addr)
 }
+
+func TestGenerateRegNetGenesisBlock(t *testing.T) {
+	block := GenerateRegNetGenesisBlock(nil)
+	assert.Equal(t, 1, len(block.Transactions))
+	assert.Equal(t, ElaAssetId, block.Header.MerkleRoot)
+	assert.Equal(t, regNetPowLimitBits, block.Header.Bits)
+	assert.Equal(t, RegNetGenesisBlock.Hash(), block.Hash())
+
+	premines := []Premine{
+		{ProgramHash: testNetFoundation, Value: 100000000},
+		{ProgramHash: mainNetFoundation, Value: 200000000},
+	}
+	block = GenerateRegNetGenesisBlock(premines)
+	assert.Equal(t, 2, len(block.Transactions))
+	coinbase := block.Transactions[1]
+	assert.True(t, coinbase.IsCoinBaseTx())
+	assert.Equal(t, 2, len(coinbase.Outputs))
+	for i, premine := range premines {
+		assert.Equal(t, ElaAssetId, coinbase.Outputs[i].AssetID)
+		assert.Equal(t, premine.ProgramHash, coinbase.Outputs[i].ProgramHash)
+		assert.Equal(t, premine.Value, coinbase.Outputs[i].Value)
+	}
+
+	// Every node given the same premines generates the same block.
+	assert.Equal(t, block.Hash(), GenerateRegNetGenesisBlock(premines).Hash())
+	assert.NotEqual(t, RegNetGenesisBlock.Hash(), block.Hash())
+}
+
+func TestActivations(t *testing.T) {
+	activations := Activations{UpgradeAnchor: 100}
+
+	assert.False(t, activations.IsActive(UpgradeAnchor, 0))
+	assert.False(t, activations.IsActive(UpgradeAnchor, 99))
+	assert.True(t, activations.IsActive(UpgradeAnchor, 100))
+	assert.True(t, activations.IsActive(UpgradeAnchor, 101))
+
+	// An upgrade missing from the schedule is never active.
+	assert.False(t, activations.IsActive(UpgradeTransfer, 0))
+	assert.False(t, activations.IsActive(UpgradeTransfer, ^uint32(0)))
+
+	assert.Equal(t, MainNetActivations, GetActivations(&MainNetParams))
+	assert.Equal(t, RegNetActivations, GetActivations(&RegNetParams))
+	assert.True(t, GetActivations(&RegNetParams).IsActive(UpgradeRecovery, 0))
+}
+
+func TestCheckpoints(t *testing.T) {
+	var checkpoints Checkpoints
+	assert.Nil(t, checkpoints.Last())
+	assert.False(t, checkpoints.IsBelowLast(0))
+
+	checkpoints = Checkpoints{
+		{Height: 1000, Hash: common.Uint256{1}},
+		{Height: 2000, Hash: common.Uint256{2}},
+	}
+	checkpoint, ok := checkpoints.Get(2000)
+	assert.True(t, ok)
+	assert.Equal(t, common.Uint256{2}, checkpoint.Hash)
+	_, ok = checkpoints.Get(1500)
+	assert.False(t, ok)
+
+	assert.Equal(t, uint32(2000), checkpoints.Last().Height)
+	assert.True(t, checkpoints.IsBelowLast(1))
+	assert.True(t, checkpoints.IsBelowLast(2000))
+	assert.False(t, checkpoints.IsBelowLast(2001))
+
+	assert.Nil(t, checkpoints.Reached(999))
+	assert.Equal(t, uint32(1000), checkpoints.Reached(1999).Height)
+	assert.Equal(t, uint32(2000), checkpoints.Reached(2000).Height)
+}
+
+func TestRewardSchedule(t *testing.T) {
+	schedule := RewardSchedule{
+		{Height: 0, FoundationShare: 0.3},
+		{Height: 1000, FoundationShare: 0.2},
+	}
+	assert.Equal(t, 0.3, schedule.FoundationShare(0))
+	assert.Equal(t, 0.3, schedule.FoundationShare(999))
+	assert.Equal(t, 0.2, schedule.FoundationShare(1000))
+	assert.Equal(t, 0.2, schedule.FoundationShare(5000))
+
+	assert.Equal(t, 0.3, GetRewardSchedule(&MainNetParams).FoundationShare(0))
+	assert.Equal(t, float64(0), GetRewardSchedule(&RegNetParams).FoundationShare(0))
+}
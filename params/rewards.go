@@ -0,0 +1,64 @@
+package params
+
+import (
+	"github.com/elastos/Elastos.ELA.SideChain/config"
+)
+
+// RewardEra is the coinbase reward rule of the blocks from a height on.
+type RewardEra struct {
+	Height uint32
+
+	// FoundationShare is the minimum share of the coinbase rewards paid to
+	// the foundation, between 0 and 1.
+	FoundationShare float64
+}
+
+// RewardSchedule is a list of reward eras in ascending height order, the
+// first one starting at the genesis block.
+type RewardSchedule []RewardEra
+
+// FoundationShare returns the minimum share of the coinbase rewards paid to
+// the foundation in the block at the height.
+func (s RewardSchedule) FoundationShare(height uint32) float64 {
+	var share float64
+	for _, era := range s {
+		if era.Height > height {
+			break
+		}
+		share = era.FoundationShare
+	}
+	return share
+}
+
+// MainNetRewardSchedule is the reward schedule of the main network.
+var MainNetRewardSchedule = RewardSchedule{
+	{Height: 0, FoundationShare: 0.3},
+}
+
+// TestNetRewardSchedule is the reward schedule of the test network.
+var TestNetRewardSchedule = RewardSchedule{
+	{Height: 0, FoundationShare: 0.3},
+}
+
+// RegNetRewardSchedule is the reward schedule of the regression test
+// network, the miners get all the rewards.
+var RegNetRewardSchedule = RewardSchedule{
+	{Height: 0, FoundationShare: 0},
+}
+
+// networkRewardSchedules are the reward schedules by network name.
+var networkRewardSchedules = map[string]RewardSchedule{
+	MainNetParams.Name: MainNetRewardSchedule,
+	TestNetParams.Name: TestNetRewardSchedule,
+	RegNetParams.Name:  RegNetRewardSchedule,
+}
+
+// GetRewardSchedule returns the reward schedule of the network of the chain
+// parameters, an unknown network has the reward schedule of the main
+// network.
+func GetRewardSchedule(chainParams *config.Params) RewardSchedule {
+	if schedule, ok := networkRewardSchedules[chainParams.Name]; ok {
+		return schedule
+	}
+	return MainNetRewardSchedule
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	chaincfg "github.com/elastos/Elastos.ELA.SideChain/config"
+	"github.com/elastos/Elastos.ELA.Utility/elalog"
+)
+
+// configReloader applies the config file again when the node receives
+// SIGHUP. The log levels, the monitor state, the mining settings and the RPC
+// allow-list change at runtime, the other values need a restart.
+type configReloader struct {
+	// running is the configuration the node started with, and current the
+	// last one loaded.
+	running     *appConfig
+	chainParams *chaincfg.Params
+	current     *appConfig
+
+	miner        *minerState
+	rpcAllowList *rpcAllowList
+}
+
+func newConfigReloader(cfg *appConfig, chainParams *chaincfg.Params,
+	miner *minerState, rpcAllowList *rpcAllowList) *configReloader {
+	return &configReloader{
+		running:      cfg,
+		chainParams:  chainParams,
+		current:      cfg,
+		miner:        miner,
+		rpcAllowList: rpcAllowList,
+	}
+}
+
+// reload resolves the configuration again and applies the values changed
+// since the last load. The running configuration is kept when the new one is
+// invalid.
+func (r *configReloader) reload() {
+	loaded, chainParams, err := resolveConfig()
+	if err != nil {
+		eladlog.Errorf("Reload config failed, keep the running config: %s", err)
+		return
+	}
+
+	var changed []string
+	current := r.current
+	if loaded.LogLevel != current.LogLevel || !reflect.DeepEqual(loaded.LogLevels, current.LogLevels) {
+		level, _ := elalog.LevelFromString(loaded.LogLevel)
+		applyLogLevels(level, loaded.LogLevels)
+		changed = append(changed, "log levels")
+	}
+	if loaded.MonitorState != current.MonitorState {
+		setMonitorState(loaded.MonitorState)
+		changed = append(changed, "monitor state "+strconv.FormatBool(loaded.MonitorState))
+	}
+	// The miner settings are read when the next block is generated.
+	r.miner.setMiner(loaded.MinerAddr, loaded.MinerInfo)
+	if loaded.MinerAddr != current.MinerAddr {
+		changed = append(changed, "pay to address "+loaded.MinerAddr)
+	}
+	if loaded.MinerInfo != current.MinerInfo {
+		changed = append(changed, "miner info "+loaded.MinerInfo)
+	}
+	// The mining may have been toggled by the RPC since the last load.
+	if r.miner.setMining(loaded.Mining) {
+		changed = append(changed, "mining "+strconv.FormatBool(loaded.Mining))
+	}
+	if !reflect.DeepEqual(loaded.RpcAllowedActions, current.RpcAllowedActions) {
+		if err := r.rpcAllowList.set(loaded.RpcAllowedActions); err != nil {
+			eladlog.Errorf("Reload RPC allow-list failed, keep the running one: %s", err)
+			loaded.RpcAllowedActions = current.RpcAllowedActions
+		} else {
+			changed = append(changed, "RPC allowed actions")
+		}
+	}
+	r.current = loaded
+
+	if len(changed) > 0 {
+		eladlog.Infof("Config reloaded, changed %s", strings.Join(changed, ", "))
+	} else {
+		eladlog.Info("Config reloaded, nothing changed")
+	}
+	if restart := r.restartRequired(loaded, chainParams); len(restart) > 0 {
+		eladlog.Warnf("Config values need a restart to apply: %s", strings.Join(restart, ", "))
+	}
+}
+
+// restartRequired returns the names of the values of the loaded configuration
+// which differ from the running ones and can not change at runtime.
+func (r *configReloader) restartRequired(loaded *appConfig, chainParams *chaincfg.Params) []string {
+	running, runningParams := r.running, r.chainParams
+	values := []struct {
+		name            string
+		running, loaded interface{}
+	}{
+		{"NetType", runningParams.Name, chainParams.Name},
+		{"GenesisBlock", runningParams.GenesisBlock.Hash(), chainParams.GenesisBlock.Hash()},
+		{"Magic", runningParams.Magic, chainParams.Magic},
+		{"NodePort", runningParams.DefaultPort, chainParams.DefaultPort},
+		{"SeedList", runningParams.SeedList, chainParams.SeedList},
+		{"FoundationAddress", runningParams.Foundation, chainParams.Foundation},
+		{"MinTxFee", runningParams.MinTransactionFee, chainParams.MinTransactionFee},
+		{"ExchangeRate", runningParams.ExchangeRate, chainParams.ExchangeRate},
+		{"MinCrossChainTxFee", runningParams.MinCrossChainTxFee, chainParams.MinCrossChainTxFee},
+		{"DisableTxFilters", runningParams.DisableTxFilters, chainParams.DisableTxFilters},
+		{"InstantBlock", runningParams.PowLimitBits, chainParams.PowLimitBits},
+		{"SpvMagic", runningParams.SpvParams.Magic, chainParams.SpvParams.Magic},
+		{"SpvSeedList", runningParams.SpvParams.SeedList, chainParams.SpvParams.SeedList},
+		{"MainChainFoundationAddress", runningParams.SpvParams.Foundation, chainParams.SpvParams.Foundation},
+		{"HttpRestPort", running.HttpRestPort, loaded.HttpRestPort},
+		{"HttpJsonPort", running.HttpJsonPort, loaded.HttpJsonPort},
+		{"HttpWsPort", running.HttpWsPort, loaded.HttpWsPort},
+		{"MaxLogsSize", running.MaxLogsFolderSize, loaded.MaxLogsFolderSize},
+		{"MaxPerLogSize", running.MaxPerLogFileSize, loaded.MaxPerLogFileSize},
+		{"LogRotation", running.LogRotation, loaded.LogRotation},
+		{"CompressLogs", running.CompressLogs, loaded.CompressLogs},
+		{"MaxLogAge", running.MaxLogAge, loaded.MaxLogAge},
+		{"LogFormat.Stdout", running.StdoutLogFormat, loaded.StdoutLogFormat},
+		{"LogFormat.File", running.FileLogFormat, loaded.FileLogFormat},
+		{"Checkpoints", running.Checkpoints, loaded.Checkpoints},
+		{"CheckpointScriptChecks", running.SkipCheckpointScripts, loaded.SkipCheckpointScripts},
+	}
+
+	var restart []string
+	for _, value := range values {
+		if !reflect.DeepEqual(value.running, value.loaded) {
+			restart = append(restart, value.name)
+		}
+	}
+	return restart
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.Utility/http/jsonrpc"
+	"github.com/elastos/Elastos.ELA.Utility/http/util"
+)
+
+// rpcActionNotAllowed is the JSON-RPC error code of the requests of an action
+// which is not allowed, the code of a method not found.
+const rpcActionNotAllowed = -32601
+
+// rpcAllowList holds the JSON-RPC actions the node serves, all of them when
+// it is empty. The allow-list is by action: the JSON-RPC server does not
+// pass the address of the client to the handlers, so it can not be by
+// address.
+type rpcAllowList struct {
+	sync.RWMutex
+	actions map[string]bool
+	known   map[string]bool
+}
+
+func newRPCAllowList() *rpcAllowList {
+	return &rpcAllowList{known: make(map[string]bool)}
+}
+
+// set replaces the allowed actions. The allow-list is kept when one of the
+// actions is not an action of the server, so a misspelt action does not deny
+// the others.
+func (l *rpcAllowList) set(actions []string) error {
+	l.Lock()
+	defer l.Unlock()
+
+	var unknown []string
+	for _, action := range actions {
+		if !l.known[action] {
+			unknown = append(unknown, action)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.New("RpcAllowedActions are not actions of the server: " + strings.Join(unknown, ", "))
+	}
+
+	l.actions = nil
+	if len(actions) > 0 {
+		l.actions = make(map[string]bool, len(actions))
+	}
+	for _, action := range actions {
+		l.actions[action] = true
+	}
+	return nil
+}
+
+func (l *rpcAllowList) allowed(action string) bool {
+	l.RLock()
+	defer l.RUnlock()
+
+	return l.actions == nil || l.actions[action]
+}
+
+// guard returns the handler of the action, which refuses the requests while
+// the action is not allowed.
+func (l *rpcAllowList) guard(action string, handler util.Handler) util.Handler {
+	l.Lock()
+	l.known[action] = true
+	l.Unlock()
+
+	return func(param util.Params) (interface{}, error) {
+		if !l.allowed(action) {
+			return nil, util.NewError(rpcActionNotAllowed, "action "+action+" is not allowed")
+		}
+		return handler(param)
+	}
+}
+
+// guardedServer registers the actions on the JSON-RPC server guarded by the
+// allow-list.
+type guardedServer struct {
+	*jsonrpc.Server
+	allowList *rpcAllowList
+}
+
+func (s *guardedServer) RegisterAction(action string, handler util.Handler, params ...string) {
+	s.Server.RegisterAction(action, s.allowList.guard(action, handler), params...)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Utility/http/util"
+)
+
+func TestRPCAllowList(t *testing.T) {
+	allowList := newRPCAllowList()
+	var called []string
+	handler := func(action string) util.Handler {
+		return allowList.guard(action, func(util.Params) (interface{}, error) {
+			called = append(called, action)
+			return nil, nil
+		})
+	}
+	getBlock, toggleMining := handler("getblock"), handler("togglemining")
+
+	// All the actions are served while the allow-list is empty.
+	getBlock(nil)
+	toggleMining(nil)
+	if len(called) != 2 {
+		t.Fatalf("called actions %v", called)
+	}
+
+	called = nil
+	if err := allowList.set([]string{"getblock"}); err != nil {
+		t.Fatalf("set allow-list failed, %s", err)
+	}
+	getBlock(nil)
+	toggleMining(nil)
+	if len(called) != 1 || called[0] != "getblock" {
+		t.Errorf("called allowed actions %v", called)
+	}
+
+	// An allow-list of unknown actions is refused, the current one is kept.
+	called = nil
+	if err := allowList.set([]string{"toggle", "mining"}); err == nil {
+		t.Errorf("allow-list of unknown actions accepted")
+	}
+	getBlock(nil)
+	toggleMining(nil)
+	if len(called) != 1 || called[0] != "getblock" {
+		t.Errorf("called actions after a refused allow-list %v", called)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
+
+	sideblockchain "github.com/elastos/Elastos.ELA.SideChain/blockchain"
+)
+
+// defaultResolveCacheSize is the number of resolutions kept in the cache.
+const defaultResolveCacheSize = 10000
+
+// resolveCache is a bounded LRU cache of the resolved paths and service
+// endpoints of IDs. Entries are removed when a block changes the index
+// entries they were read from.
+type resolveCache struct {
+	sync.Mutex
+	capacity   int
+	entries    map[string]*list.Element
+	order      *list.List
+	hits       uint64
+	misses     uint64
+	generation uint64
+}
+
+type resolveCacheEntry struct {
+	key   string
+	value interface{}
+}
+
+func newResolveCache(capacity int) *resolveCache {
+	return &resolveCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func pathCacheKey(ID, path string) string {
+	return "p" + ID + path
+}
+
+func serviceCacheKey(ID string) string {
+	return "s" + ID
+}
+
+func (c *resolveCache) get(key string) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*resolveCacheEntry).value, true
+}
+
+// currentGeneration returns a number changed with every invalidation.
+func (c *resolveCache) currentGeneration() uint64 {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.generation
+}
+
+// addIf adds the value if the cache has not been invalidated since the
+// generation, which was read before the value was loaded.
+func (c *resolveCache) addIf(key string, value interface{}, generation uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*resolveCacheEntry).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&resolveCacheEntry{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resolveCacheEntry).key)
+	}
+}
+
+// invalidate removes the entries read from the changed index entries.
+func (c *resolveCache) invalidate(keys [][]byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if len(key) == 0 {
+			continue
+		}
+
+		var cacheKey string
+		switch key[0] {
+		case byte(sideblockchain.IX_Identification):
+			cacheKey = "p" + string(key[1:])
+		case byte(blockchain.IX_ServiceEndpoint):
+			cacheKey = "s" + string(key[1:])
+		default:
+			continue
+		}
+
+		if element, ok := c.entries[cacheKey]; ok {
+			c.order.Remove(element)
+			delete(c.entries, cacheKey)
+		}
+	}
+}
+
+func (c *resolveCache) stats() *ResolveCacheInfo {
+	c.Lock()
+	defer c.Unlock()
+
+	return &ResolveCacheInfo{
+		Capacity: c.capacity,
+		Size:     c.order.Len(),
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}
+}
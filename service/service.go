@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
 	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
@@ -11,41 +14,169 @@ import (
 	"github.com/elastos/Elastos.ELA.SideChain/service"
 	"github.com/elastos/Elastos.ELA.SideChain/types"
 	"github.com/elastos/Elastos.ELA.Utility/common"
+	"github.com/elastos/Elastos.ELA.Utility/elalog"
 	"github.com/elastos/Elastos.ELA.Utility/http/util"
 )
 
+const (
+	// defaultChangesLimit is the default number of changes returned by
+	// getidentificationchanges, and maxChangesLimit the maximum.
+	defaultChangesLimit = 1000
+	maxChangesLimit     = 10000
+
+	// defaultListLimit is the default number of IDs returned by
+	// listidentifications, and maxListLimit the maximum.
+	defaultListLimit = 100
+	maxListLimit     = 1000
+
+	// maxListScan is the number of index entries listidentifications scans
+	// at most in one call, so a filter matching few IDs can not stall the
+	// node.
+	maxListScan = 100000
+
+	// defaultStatsDays is the default number of days of registrations
+	// returned by getidentificationstats, and maxStatsDays the maximum.
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+)
+
+const (
+	// DIDPrefix is the method prefix of the DIDs of the IDs on this chain.
+	DIDPrefix = "did:elastos:"
+
+	// DIDContext is the JSON-LD context of the returned DID documents.
+	DIDContext = "https://www.w3.org/ns/did/v1"
+)
+
 type HttpServiceExtend struct {
 	*service.HttpService
 
 	Config *service.Config
 	store  *blockchain.IDChainStore
+	cache  *resolveCache
+
+	// setSubsystemLogLevel sets the level of the logger of a subsystem.
+	setSubsystemLogLevel func(subsystem string, level elalog.Level) error
 }
 
-func NewHttpService(cfg *service.Config, store *blockchain.IDChainStore) *HttpServiceExtend {
+func NewHttpService(cfg *service.Config, store *blockchain.IDChainStore,
+	setSubsystemLogLevel func(subsystem string, level elalog.Level) error) *HttpServiceExtend {
 	server := &HttpServiceExtend{
-		HttpService: service.NewHttpService(cfg),
-		store:       store,
-		Config:      cfg,
+		HttpService:          service.NewHttpService(cfg),
+		store:                store,
+		Config:               cfg,
+		cache:                newResolveCache(defaultResolveCacheSize),
+		setSubsystemLogLevel: setSubsystemLogLevel,
 	}
+	store.RegisterIndexListener(server.cache.invalidate)
 	return server
 }
 
 func (s *HttpServiceExtend) GetIdentificationTxByIdAndPath(param util.Params) (interface{}, error) {
+	id, resolved, err := s.getIdentificationTx(param)
+	if err != nil {
+		return nil, err
+	}
+	filterExpired, _ := param.Bool("filterexpired")
+	withProof, _ := param.Bool("proof")
+
+	txInfo := s.Config.GetTransactionInfo(s.Config, resolved.header, resolved.txn)
+	selectBatchEntry(txInfo, resolved.txn, id)
+	if err := s.markExpiredValues(txInfo, filterExpired); err != nil {
+		return nil, err
+	}
+
+	services, err := s.getServiceEndpoints(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IdentificationTxInfo{
+		TransactionInfo: txInfo,
+		Services:        services,
+	}
+	if withProof {
+		if result.Proof, err = s.getTransactionProof(resolved.txn, resolved.height); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// SetLogLevel sets the level of the logger of the subsystem given by the
+// subsystem parameter, or of all the loggers without it.
+func (s *HttpServiceExtend) SetLogLevel(param util.Params) (interface{}, error) {
+	subsystem, ok := param.String("subsystem")
+	if !ok {
+		return s.HttpService.SetLogLevel(param)
+	}
+	level, ok := param.Uint("level")
+	if !ok || level > uint32(elalog.LevelFatal) && level != uint32(elalog.LevelOff) {
+		return nil, util.NewError(int(service.InvalidParams), "invalid level")
+	}
+	if err := s.setSubsystemLogLevel(subsystem, elalog.Level(level)); err != nil {
+		return nil, util.NewError(int(service.InvalidParams), err.Error())
+	}
+
+	return "log level of " + strings.ToUpper(subsystem) + " has been set to " +
+		strconv.FormatUint(uint64(level), 10), nil
+}
+
+// GetIdentificationProof returns the proof that the transaction which
+// registered the path of an ID is included in the chain.
+func (s *HttpServiceExtend) GetIdentificationProof(param util.Params) (interface{}, error) {
+	_, resolved, err := s.getIdentificationTx(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getTransactionProof(resolved.txn, resolved.height)
+}
+
+// GetIdentificationCacheStats returns the size and the hit and miss counters
+// of the cache of resolved IDs.
+func (s *HttpServiceExtend) GetIdentificationCacheStats(param util.Params) (interface{}, error) {
+	return s.cache.stats(), nil
+}
+
+// resolvedPath is the transaction that registered a path of an ID, with the
+// height and header of its block.
+type resolvedPath struct {
+	txn    *types.Transaction
+	height uint32
+	header *types.Header
+}
+
+// getIdentificationTx returns the transaction that registered the path of
+// the ID given by the id and path parameters, along with its block.
+func (s *HttpServiceExtend) getIdentificationTx(param util.Params) (string, *resolvedPath, error) {
 	id, ok := param.String("id")
 	if !ok {
-		return nil, util.NewError(int(service.InvalidParams), "id is null")
+		return "", nil, util.NewError(int(service.InvalidParams), "id is null")
 	}
 	_, err := common.Uint168FromAddress(id)
 	if err != nil {
-		return nil, util.NewError(int(service.InvalidParams), "invalid id")
+		return "", nil, util.NewError(int(service.InvalidParams), "invalid id")
 	}
 	path, ok := param.String("path")
 	if !ok {
-		return nil, util.NewError(int(service.InvalidParams), "path is null")
+		return "", nil, util.NewError(int(service.InvalidParams), "path is null")
+	}
+
+	value, err := s.cached(pathCacheKey(id, path), func() (interface{}, error) {
+		return s.resolvePath(id, path)
+	})
+	if err != nil {
+		return "", nil, err
 	}
 
+	return id, value.(*resolvedPath), nil
+}
+
+func (s *HttpServiceExtend) resolvePath(ID, path string) (*resolvedPath, error) {
 	buf := new(bytes.Buffer)
-	buf.WriteString(id)
+	buf.WriteString(ID)
 	buf.WriteString(path)
 	txHashBytes, err := s.store.GetRegisterIdentificationTx(buf.Bytes())
 	if err != nil {
@@ -69,7 +200,634 @@ func (s *HttpServiceExtend) GetIdentificationTxByIdAndPath(param util.Params) (i
 		return nil, util.NewError(int(service.UnknownBlock), "get header failed")
 	}
 
-	return s.Config.GetTransactionInfo(s.Config, header, txn), nil
+	return &resolvedPath{txn: txn, height: height, header: header}, nil
+}
+
+// cached returns the value cached for key, or loads it and caches it unless
+// the identification indexes changed while loading. Errors are not cached.
+// The changes of the committed blocks are synced first, and a value loaded
+// before a commit which is synced later is removed by that sync.
+func (s *HttpServiceExtend) cached(key string, load func() (interface{}, error)) (interface{}, error) {
+	s.store.SyncIndexListeners()
+	if value, ok := s.cache.get(key); ok {
+		return value, nil
+	}
+
+	generation := s.cache.currentGeneration()
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	s.cache.addIf(key, value, generation)
+
+	return value, nil
+}
+
+// getTransactionProof returns the raw transaction, the raw header of its
+// block and the merkle branch from the transaction to the header.
+func (s *HttpServiceExtend) getTransactionProof(txn *types.Transaction, height uint32) (*TransactionProofInfo, error) {
+	bHash, err := s.store.GetBlockHash(height)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownBlock), "get block hash failed")
+	}
+	block, err := s.store.GetBlock(bHash)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownBlock), "get block failed")
+	}
+	proof, err := blockchain.GetMerkleProof(block, txn.Hash())
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get merkle proof failed")
+	}
+
+	txBuf := new(bytes.Buffer)
+	if err := txn.Serialize(txBuf); err != nil {
+		return nil, util.NewError(int(service.InternalError), "serialize transaction failed")
+	}
+	headerBuf := new(bytes.Buffer)
+	if err := block.Header.Serialize(headerBuf); err != nil {
+		return nil, util.NewError(int(service.InternalError), "serialize header failed")
+	}
+
+	return &TransactionProofInfo{
+		TxId:      service.ToReversedString(txn.Hash()),
+		Tx:        common.BytesToHexString(txBuf.Bytes()),
+		BlockHash: service.ToReversedString(bHash),
+		Height:    height,
+		Time:      block.Header.Timestamp,
+		Header:    common.BytesToHexString(headerBuf.Bytes()),
+		Proof:     getMerkleProofInfo(proof, block.Header.MerkleRoot),
+	}, nil
+}
+
+// GetDIDDocument returns the DID document of an ID.
+func (s *HttpServiceExtend) GetDIDDocument(param util.Params) (interface{}, error) {
+	id, ok := param.String("id")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "id is null")
+	}
+	_, err := common.Uint168FromAddress(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid id")
+	}
+
+	services, err := s.getServiceEndpoints(id)
+	if err != nil {
+		return nil, err
+	}
+
+	controller, err := s.store.GetIdentificationController(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get controller failed")
+	}
+
+	did := DIDPrefix + id
+	doc := &DIDDocument{
+		Context: DIDContext,
+		Id:      did,
+	}
+	if controller != id {
+		doc.Controller = DIDPrefix + controller
+	}
+	for i, endpoint := range services {
+		doc.Service = append(doc.Service, DIDServiceInfo{
+			Id:              did + "#service-" + strconv.Itoa(i),
+			Type:            endpoint.Type,
+			ServiceEndpoint: endpoint.Endpoint,
+		})
+	}
+
+	return doc, nil
+}
+
+// GetIdentificationControllers returns the current controller of an ID and
+// the transfers that led to it.
+func (s *HttpServiceExtend) GetIdentificationControllers(param util.Params) (interface{}, error) {
+	id, ok := param.String("id")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "id is null")
+	}
+	_, err := common.Uint168FromAddress(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid id")
+	}
+
+	records, err := s.store.GetIdentificationControllers(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get controllers failed")
+	}
+	sequence, err := s.store.GetIdentificationSequence(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get sequence failed")
+	}
+
+	result := &IdentificationControllersInfo{
+		Id:         id,
+		Controller: id,
+		Sequence:   sequence,
+		History:    make([]ControllerRecordInfo, 0, len(records)),
+	}
+	for _, record := range records {
+		result.Controller = record.Controller
+		result.History = append(result.History, ControllerRecordInfo{
+			Controller: record.Controller,
+			TxId:       service.ToReversedString(record.TxHash),
+			Height:     record.Height,
+		})
+	}
+
+	return result, nil
+}
+
+// GetIdentificationRecovery returns the recovery guardians of an ID and its
+// pending recovery if any.
+func (s *HttpServiceExtend) GetIdentificationRecovery(param util.Params) (interface{}, error) {
+	id, ok := param.String("id")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "id is null")
+	}
+	_, err := common.Uint168FromAddress(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid id")
+	}
+
+	guardians, err := s.store.GetRecoveryGuardians(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get guardians failed")
+	}
+	recovery, err := s.store.GetPendingRecovery(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get pending recovery failed")
+	}
+
+	result := &IdentificationRecoveryInfo{
+		Id:        id,
+		Guardians: []string{},
+	}
+	if guardians != nil {
+		result.Guardians = guardians.Guardians
+		result.Threshold = guardians.Threshold
+		result.Delay = guardians.Delay
+	}
+	if recovery != nil {
+		result.Pending = &PendingRecoveryInfo{
+			NewController:   recovery.NewController,
+			TxId:            service.ToReversedString(recovery.TxHash),
+			Height:          recovery.Height,
+			EffectiveHeight: recovery.EffectiveHeight(),
+		}
+	}
+
+	return result, nil
+}
+
+// GetAnchor returns the transaction that first anchored a hash, with the
+// proof that it is included in the chain.
+func (s *HttpServiceExtend) GetAnchor(param util.Params) (interface{}, error) {
+	hashStr, ok := param.String("hash")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "hash is null")
+	}
+	hashBytes, err := common.HexStringToBytes(hashStr)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid hash")
+	}
+	hash, err := common.Uint256FromBytes(common.BytesReverse(hashBytes))
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid hash")
+	}
+
+	txHash, err := s.store.GetAnchorTx(*hash)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownTransaction), "hash is not anchored")
+	}
+	txn, height, err := s.store.GetTransaction(*txHash)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownTransaction), "get transaction failed")
+	}
+	proof, err := s.getTransactionProof(txn, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnchorInfo{
+		Hash:                 service.ToReversedString(*hash),
+		Memo:                 txn.Payload.(*id.PayloadAnchor).Memo,
+		TransactionProofInfo: proof,
+	}, nil
+}
+
+// GetIdentificationStateRoot returns the root of the identification state
+// tree after the block at the height, the best block by default.
+func (s *HttpServiceExtend) GetIdentificationStateRoot(param util.Params) (interface{}, error) {
+	height, ok := param.Uint("height")
+	if !ok {
+		height = s.store.GetHeight()
+	}
+
+	root, err := s.store.GetStateRoot(height)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownBlock), "get state root failed")
+	}
+
+	return &StateRootInfo{
+		Height: height,
+		Root:   common.BytesToHexString(root.Bytes()),
+	}, nil
+}
+
+// GetIdentificationStateProof returns the proof of the content registered for
+// the path of an ID, or of its absence, under the state root after the block
+// at the height, the best block by default.
+func (s *HttpServiceExtend) GetIdentificationStateProof(param util.Params) (interface{}, error) {
+	id, ok := param.String("id")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "id is null")
+	}
+	_, err := common.Uint168FromAddress(id)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid id")
+	}
+	path, ok := param.String("path")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "path is null")
+	}
+	height, ok := param.Uint("height")
+	if !ok {
+		height = s.store.GetHeight()
+	}
+
+	key := blockchain.StateKey(id, path)
+	root, proof, err := s.store.GetStateProof(height, key)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get state proof failed")
+	}
+
+	info := &StateProofInfo{
+		Height:   height,
+		Root:     common.BytesToHexString(root.Bytes()),
+		Key:      common.BytesToHexString(key.Bytes()),
+		Siblings: make([]string, 0, len(proof.Siblings)),
+	}
+	for _, sibling := range proof.Siblings {
+		info.Siblings = append(info.Siblings, common.BytesToHexString(sibling.Bytes()))
+	}
+	if proof.Leaf != nil {
+		info.Leaf = &StateLeafInfo{
+			Key:   common.BytesToHexString(proof.Leaf.Key.Bytes()),
+			Value: common.BytesToHexString(proof.Leaf.Value.Bytes()),
+		}
+		if proof.Leaf.Key.IsEqual(key) {
+			info.Value = info.Leaf.Value
+		}
+	}
+
+	return info, nil
+}
+
+// GetIdentificationChanges returns the changes of IDs made by the blocks from
+// start to end, at most limit changes starting at the index-th change of the
+// start block. The next field of the result is where to continue from, it is
+// null when all the changes of the range have been returned.
+func (s *HttpServiceExtend) GetIdentificationChanges(param util.Params) (interface{}, error) {
+	start, ok := param.Uint("start")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "start is null")
+	}
+	bestHeight := s.store.GetHeight()
+	end, ok := param.Uint("end")
+	if !ok || end > bestHeight {
+		end = bestHeight
+	}
+	if start > end {
+		return nil, util.NewError(int(service.InvalidParams), "start is greater than end")
+	}
+	index, _ := param.Uint("index")
+	limit, ok := param.Uint("limit")
+	if !ok || limit == 0 {
+		limit = defaultChangesLimit
+	}
+	if limit > maxChangesLimit {
+		return nil, util.NewError(int(service.InvalidParams), "limit is too large")
+	}
+
+	result := &IdentificationChangesInfo{
+		Start:   start,
+		End:     end,
+		Changes: []IdentificationChangeInfo{},
+	}
+	err := s.store.ForEachIdentificationChanges(start, end,
+		func(height uint32, changes []blockchain.IdentificationChange) bool {
+			first := uint32(0)
+			if height == start {
+				first = index
+			}
+			for i := first; i < uint32(len(changes)); i++ {
+				if uint32(len(result.Changes)) == limit {
+					result.Next = &ChangesCursorInfo{Height: height, Index: i}
+					return false
+				}
+				change := changes[i]
+				result.Changes = append(result.Changes, IdentificationChangeInfo{
+					Height: height,
+					Type:   changeTypeNames[change.Type],
+					Id:     change.ID,
+					Path:   change.Path,
+					TxId:   service.ToReversedString(change.TxHash),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get identification changes failed")
+	}
+
+	return result, nil
+}
+
+// ListIdentifications lists the registered IDs in address order, the IDs
+// which are not addresses by their length first, with the paths matching the
+// filters. Only IDs with a matching path are listed. The next field of the
+// result is the cursor to pass to get the following IDs, it is empty when the
+// last ID has been listed.
+func (s *HttpServiceExtend) ListIdentifications(param util.Params) (interface{}, error) {
+	cursor, _ := param.String("cursor")
+	pathPrefix, _ := param.String("pathprefix")
+	hasPath, filterPath := param.String("haspath")
+	registeredAfter, filterHeight := param.Uint("registeredafter")
+	limit, ok := param.Uint("limit")
+	if !ok || limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		return nil, util.NewError(int(service.InvalidParams), "limit is too large")
+	}
+
+	result := &IdentificationListInfo{
+		Identifications: []IdentificationListItemInfo{},
+	}
+	var current *IdentificationListItemInfo
+	var scanned int
+	var iterErr error
+	err := s.store.ForEachIdentificationPath(cursor, func(ID string, path string, txHash common.Uint256) bool {
+		if current != nil && current.Id != ID {
+			if len(current.Paths) > 0 {
+				result.Identifications = append(result.Identifications, *current)
+			}
+			if uint32(len(result.Identifications)) == limit || scanned >= maxListScan {
+				result.Next = current.Id
+				current = nil
+				return false
+			}
+			current = nil
+		}
+		if current == nil {
+			current = &IdentificationListItemInfo{Id: ID, Paths: []IdentificationPathInfo{}}
+		}
+		scanned++
+
+		if filterPath && path != hasPath {
+			return true
+		}
+		if !strings.HasPrefix(path, pathPrefix) {
+			return true
+		}
+		if filterHeight {
+			height, err := s.store.GetRegistrationHeight(ID, path)
+			if err != nil {
+				// The path was registered before the node indexed the
+				// heights, fall back to the registering transaction.
+				if _, height, err = s.store.GetTransaction(txHash); err != nil {
+					iterErr = err
+					return false
+				}
+			}
+			if height <= registeredAfter {
+				return true
+			}
+		}
+
+		current.Paths = append(current.Paths, IdentificationPathInfo{
+			Path: path,
+			TxId: service.ToReversedString(txHash),
+		})
+		return true
+	})
+	if err != nil || iterErr != nil {
+		return nil, util.NewError(int(service.InternalError), "list identifications failed")
+	}
+	if current != nil && len(current.Paths) > 0 {
+		result.Identifications = append(result.Identifications, *current)
+	}
+
+	return result, nil
+}
+
+// GetIdentificationsByAddress lists the IDs registered by transactions
+// spending from the address, ordered by ID after the cursor.
+func (s *HttpServiceExtend) GetIdentificationsByAddress(param util.Params) (interface{}, error) {
+	address, ok := param.String("address")
+	if !ok {
+		return nil, util.NewError(int(service.InvalidParams), "address is null")
+	}
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidParams), "invalid address")
+	}
+	cursor, _ := param.String("cursor")
+	limit, ok := param.Uint("limit")
+	if !ok || limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		return nil, util.NewError(int(service.InvalidParams), "limit is too large")
+	}
+
+	result := &AddressIdentificationsInfo{
+		Identifications: []AddressIdentificationInfo{},
+	}
+	err = s.store.ForEachAddressIdentification(*programHash, cursor, func(ID string, txHash common.Uint256) bool {
+		if uint32(len(result.Identifications)) == limit {
+			result.Next = result.Identifications[limit-1].Id
+			return false
+		}
+		result.Identifications = append(result.Identifications, AddressIdentificationInfo{
+			Id:   ID,
+			TxId: service.ToReversedString(txHash),
+		})
+		return true
+	})
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "list identifications failed")
+	}
+
+	return result, nil
+}
+
+// GetIdentificationStats returns the counters of the identification registry,
+// and the registrations of the last days up to the best block.
+func (s *HttpServiceExtend) GetIdentificationStats(param util.Params) (interface{}, error) {
+	days, ok := param.Uint("days")
+	if !ok || days == 0 {
+		days = defaultStatsDays
+	}
+	if days > maxStatsDays {
+		return nil, util.NewError(int(service.InvalidParams), "days is too large")
+	}
+
+	stats, err := s.store.GetIdentificationStats()
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get identification stats failed")
+	}
+
+	bHash, err := s.store.GetBlockHash(s.store.GetHeight())
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownBlock), "get best block failed")
+	}
+	best, err := s.store.GetHeader(bHash)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownBlock), "get best header failed")
+	}
+	end := blockchain.DayOf(best.Timestamp)
+	start := uint32(0)
+	if end >= days {
+		start = end - days + 1
+	}
+	daily, err := s.store.GetDailyRegistrations(start, end)
+	if err != nil {
+		return nil, util.NewError(int(service.InternalError), "get daily registrations failed")
+	}
+
+	result := &IdentificationStatsInfo{
+		TotalIDs:      stats.TotalIDs,
+		TotalPaths:    stats.TotalPaths,
+		Registrations: stats.Registrations,
+		Revocations:   stats.Revocations,
+		Daily:         make([]DailyRegistrationsInfo, 0, len(daily)),
+	}
+	for _, day := range daily {
+		result.Daily = append(result.Daily, DailyRegistrationsInfo{
+			Date:          time.Unix(int64(day.Day)*24*60*60, 0).UTC().Format("2006-01-02"),
+			Registrations: day.Registrations,
+		})
+	}
+
+	return result, nil
+}
+
+var changeTypeNames = map[byte]string{
+	blockchain.ChangePath:             "path",
+	blockchain.ChangeServiceEndpoints: "services",
+	blockchain.ChangeController:       "controller",
+	blockchain.ChangeRecovery:         "recovery",
+}
+
+// getServiceEndpoints returns the service endpoints registered for the ID,
+// or nil if the ID has none.
+func (s *HttpServiceExtend) getServiceEndpoints(ID string) ([]ServiceEndpointInfo, error) {
+	value, err := s.cached(serviceCacheKey(ID), func() (interface{}, error) {
+		return s.loadServiceEndpoints(ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]ServiceEndpointInfo), nil
+}
+
+func (s *HttpServiceExtend) loadServiceEndpoints(ID string) ([]ServiceEndpointInfo, error) {
+	txHashBytes, err := s.store.GetServiceEndpointTx(ID)
+	if err != nil {
+		return nil, nil
+	}
+	txHash, err := common.Uint256FromBytes(txHashBytes)
+	if err != nil {
+		return nil, util.NewError(int(service.InvalidTransaction), "invalid transaction hash")
+	}
+
+	txn, _, err := s.store.GetTransaction(*txHash)
+	if err != nil {
+		return nil, util.NewError(int(service.UnknownTransaction), "get transaction failed")
+	}
+	payload, ok := txn.Payload.(*id.PayloadRegisterServiceEndpoint)
+	if !ok {
+		return nil, util.NewError(int(service.InvalidTransaction), "invalid service endpoint transaction")
+	}
+
+	return getServiceEndpointInfos(payload.Endpoints), nil
+}
+
+// selectBatchEntry replaces the payload of a batch registration in txInfo with
+// the entry of the ID, so it reads like a single registration of the ID.
+func selectBatchEntry(txInfo *service.TransactionInfo, txn *types.Transaction, ID string) {
+	batch, ok := txn.Payload.(*id.PayloadRegisterIdentificationBatch)
+	if !ok {
+		return
+	}
+	if entry := batch.Entry(ID); entry != nil {
+		txInfo.Payload = getRegisterIdentificationInfo(entry)
+	}
+}
+
+// markExpiredValues flags the identification values in txInfo that have
+// expired at the current best block, and drops them if filter is true.
+func (s *HttpServiceExtend) markExpiredValues(txInfo *service.TransactionInfo, filter bool) error {
+	info, ok := txInfo.Payload.(*RegisterIdentificationInfo)
+	if !ok {
+		return nil
+	}
+	if !hasExpiry(info) {
+		return nil
+	}
+
+	bestHeight := s.store.GetHeight()
+	bHash, err := s.store.GetBlockHash(bestHeight)
+	if err != nil {
+		return util.NewError(int(service.UnknownBlock), "get best block failed")
+	}
+	best, err := s.store.GetHeader(bHash)
+	if err != nil {
+		return util.NewError(int(service.UnknownBlock), "get best header failed")
+	}
+
+	for i, content := range info.Contents {
+		values := make([]RegisterIdentificationValueInfo, 0, len(content.Values))
+		for _, value := range content.Values {
+			if value.Expiry != nil {
+				value.Expiry.Expired = isExpired(value.Expiry, bestHeight, best.Timestamp)
+				if value.Expiry.Expired && filter {
+					continue
+				}
+			}
+			values = append(values, value)
+		}
+		info.Contents[i].Values = values
+	}
+
+	return nil
+}
+
+// hasExpiry returns whether any value of the registration expires, so the
+// best header is only read when needed.
+func hasExpiry(info *RegisterIdentificationInfo) bool {
+	for _, content := range info.Contents {
+		for _, value := range content.Values {
+			if value.Expiry != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isExpired(expiry *ExpiryInfo, height, timestamp uint32) bool {
+	value := id.RegisterIdentificationValue{Expiry: expiry.Value}
+	switch expiry.Type {
+	case "height":
+		value.ExpiryType = id.ExpiryHeight
+	case "timestamp":
+		value.ExpiryType = id.ExpiryTimestamp
+	}
+	return value.IsExpired(height, timestamp)
 }
 
 func (s *HttpServiceExtend) ListUnspent(param util.Params) (interface{}, error) {
@@ -160,6 +918,20 @@ func GetTransactionInfoFromBytes(txInfoBytes []byte) (*service.TransactionInfo,
 		assetInfo = &service.TransferCrossChainAssetInfo{}
 	case id.RegisterIdentification:
 		assetInfo = &RegisterIdentificationInfo{}
+	case id.RegisterServiceEndpoint:
+		assetInfo = &RegisterServiceEndpointInfo{}
+	case id.TransferIdentification:
+		assetInfo = &TransferIdentificationInfo{}
+	case id.SetRecoveryGuardians:
+		assetInfo = &SetRecoveryGuardiansInfo{}
+	case id.RecoverIdentification:
+		assetInfo = &RecoverIdentificationInfo{}
+	case id.CancelRecovery:
+		assetInfo = &CancelRecoveryInfo{}
+	case id.RegisterIdentificationBatch:
+		assetInfo = &RegisterIdentificationBatchInfo{}
+	case id.Anchor:
+		assetInfo = &AnchorPayloadInfo{}
 	default:
 		return nil, errors.New("GetBlockTransactions: Unknown payload type")
 	}
@@ -274,27 +1046,173 @@ func GetPayloadInfo(p types.Payload, pVersion byte) service.PayloadInfo {
 			return obj
 		}
 	case *id.PayloadRegisterIdentification:
-		obj := new(RegisterIdentificationInfo)
+		return getRegisterIdentificationInfo(object)
+	case *id.PayloadRegisterIdentificationBatch:
+		obj := new(RegisterIdentificationBatchInfo)
+		obj.Entries = make([]*RegisterIdentificationInfo, 0, len(object.Entries))
+		for i := range object.Entries {
+			obj.Entries = append(obj.Entries, getRegisterIdentificationInfo(&object.Entries[i]))
+		}
+		return obj
+	case *id.PayloadRegisterServiceEndpoint:
+		obj := new(RegisterServiceEndpointInfo)
 		obj.Id = object.ID
-		obj.Sign = common.BytesToHexString(object.Sign)
-		contents := []RegisterIdentificationContentInfo{}
-		for _, content := range object.Contents {
-			values := []RegisterIdentificationValueInfo{}
-			for _, value := range content.Values {
-				values = append(values, RegisterIdentificationValueInfo{
-					DataHash: service.ToReversedString(value.DataHash),
-					Proof:    value.Proof,
-					Info:     value.Info,
-				})
-			}
-
-			contents = append(contents, RegisterIdentificationContentInfo{
-				Path:   content.Path,
-				Values: values,
-			})
+		obj.Sequence = object.Sequence
+		obj.Endpoints = getServiceEndpointInfos(object.Endpoints)
+		return obj
+	case *id.PayloadTransferIdentification:
+		obj := new(TransferIdentificationInfo)
+		obj.Id = object.ID
+		obj.Sequence = object.Sequence
+		obj.NewController = object.NewController
+		return obj
+	case *id.PayloadSetRecoveryGuardians:
+		obj := new(SetRecoveryGuardiansInfo)
+		obj.Id = object.ID
+		obj.Sequence = object.Sequence
+		obj.Guardians = object.Guardians
+		obj.Threshold = object.Threshold
+		obj.Delay = object.Delay
+		return obj
+	case *id.PayloadRecoverIdentification:
+		obj := new(RecoverIdentificationInfo)
+		obj.Id = object.ID
+		obj.Sequence = object.Sequence
+		obj.NewController = object.NewController
+		return obj
+	case *id.PayloadCancelRecovery:
+		obj := new(CancelRecoveryInfo)
+		obj.Id = object.ID
+		obj.Sequence = object.Sequence
+		return obj
+	case *id.PayloadAnchor:
+		obj := new(AnchorPayloadInfo)
+		obj.Hashes = make([]string, 0, len(object.Hashes))
+		for _, hash := range object.Hashes {
+			obj.Hashes = append(obj.Hashes, service.ToReversedString(hash))
 		}
-		obj.Contents = contents
+		obj.Memo = object.Memo
 		return obj
 	}
 	return nil
 }
+
+func getRegisterIdentificationInfo(object *id.PayloadRegisterIdentification) *RegisterIdentificationInfo {
+	obj := new(RegisterIdentificationInfo)
+	obj.Id = object.ID
+	obj.Sign = common.BytesToHexString(object.Sign)
+	contents := []RegisterIdentificationContentInfo{}
+	for _, content := range object.Contents {
+		values := []RegisterIdentificationValueInfo{}
+		for _, value := range content.Values {
+			valueInfo := RegisterIdentificationValueInfo{
+				DataHash: service.ToReversedString(value.DataHash),
+				Proof:    value.Proof,
+				Info:     value.Info,
+				Expiry:   getExpiryInfo(&value),
+			}
+			if envelope, err := id.ParseEncryptedInfo(value.Info); err == nil {
+				valueInfo.Info = ""
+				valueInfo.Encrypted = true
+				valueInfo.EncryptedInfo = getEncryptedInfo(envelope, value.Info)
+			}
+			values = append(values, valueInfo)
+		}
+
+		contents = append(contents, RegisterIdentificationContentInfo{
+			Path:   content.Path,
+			Values: values,
+		})
+	}
+	obj.Contents = contents
+	return obj
+}
+
+func getMerkleProofInfo(proof *blockchain.MerkleProof, root common.Uint256) *MerkleProofInfo {
+	branch := make([]string, 0, len(proof.Branch))
+	for _, hash := range proof.Branch {
+		branch = append(branch, service.ToReversedString(hash))
+	}
+	return &MerkleProofInfo{
+		Index:      proof.Index,
+		TxCount:    proof.TxCount,
+		Branch:     branch,
+		MerkleRoot: service.ToReversedString(root),
+	}
+}
+
+func getServiceEndpointInfos(endpoints []id.ServiceEndpoint) []ServiceEndpointInfo {
+	infos := make([]ServiceEndpointInfo, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		infos = append(infos, ServiceEndpointInfo{
+			Type:     endpoint.Type,
+			Endpoint: endpoint.Endpoint,
+		})
+	}
+	return infos
+}
+
+func getExpiryInfo(value *id.RegisterIdentificationValue) *ExpiryInfo {
+	switch value.ExpiryType {
+	case id.ExpiryHeight:
+		return &ExpiryInfo{Type: "height", Value: value.Expiry}
+	case id.ExpiryTimestamp:
+		return &ExpiryInfo{Type: "timestamp", Value: value.Expiry}
+	}
+	return nil
+}
+
+func getEncryptedInfo(envelope *id.EncryptedInfo, info string) *EncryptedInfoInfo {
+	recipients := make([]string, 0, len(envelope.Recipients))
+	for _, key := range envelope.RecipientKeys() {
+		recipients = append(recipients, common.BytesToHexString(key))
+	}
+	return &EncryptedInfoInfo{
+		Recipients: recipients,
+		Envelope:   info,
+	}
+}
+
+// VerifyTransactionProof checks a proof returned by the node against the
+// headers trusted by the caller, and returns the proven transaction.
+func VerifyTransactionProof(chain blockchain.HeaderChain, info *TransactionProofInfo) (*types.Transaction, error) {
+	txBytes, err := common.HexStringToBytes(info.Tx)
+	if err != nil {
+		return nil, errors.New("invalid transaction")
+	}
+	var txn types.Transaction
+	if err := txn.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, errors.New("invalid transaction")
+	}
+
+	headerBytes, err := common.HexStringToBytes(info.Header)
+	if err != nil {
+		return nil, errors.New("invalid header")
+	}
+	var header types.Header
+	if err := header.Deserialize(bytes.NewReader(headerBytes)); err != nil {
+		return nil, errors.New("invalid header")
+	}
+
+	if info.Proof == nil {
+		return nil, errors.New("no merkle proof")
+	}
+	proof := &blockchain.MerkleProof{Index: info.Proof.Index, TxCount: info.Proof.TxCount}
+	for _, node := range info.Proof.Branch {
+		nodeBytes, err := common.HexStringToBytes(node)
+		if err != nil {
+			return nil, errors.New("invalid merkle branch")
+		}
+		hash, err := common.Uint256FromBytes(common.BytesReverse(nodeBytes))
+		if err != nil {
+			return nil, errors.New("invalid merkle branch")
+		}
+		proof.Branch = append(proof.Branch, *hash)
+	}
+
+	if err := blockchain.VerifyTransactionProof(chain, &header, txn.Hash(), proof); err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
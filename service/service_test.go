@@ -0,0 +1,176 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SideChain.ID/blockchain"
+	"github.com/elastos/Elastos.ELA.SideChain.ID/params"
+	id "github.com/elastos/Elastos.ELA.SideChain.ID/types"
+
+	"github.com/elastos/Elastos.ELA.SideChain/types"
+	"github.com/elastos/Elastos.ELA.Utility/common"
+	"github.com/elastos/Elastos.ELA.Utility/http/util"
+)
+
+// newTestService returns a service on a chain store of the regression test
+// network in a temporary folder, and the function removing it.
+func newTestService(t *testing.T) (*HttpServiceExtend, func()) {
+	dir, err := ioutil.TempDir("", "idservice")
+	if err != nil {
+		t.Fatal("create data folder error:", err)
+	}
+	store, err := blockchain.NewChainStore(params.RegNetGenesisBlock, params.RegNetActivations, nil, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("open chain store error:", err)
+	}
+	s := &HttpServiceExtend{store: store, cache: newResolveCache(defaultResolveCacheSize)}
+	store.RegisterIndexListener(s.cache.invalidate)
+	return s, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// saveTestBlock saves a block registering the paths of the ID on top of the
+// best block.
+func saveTestBlock(t *testing.T, store *blockchain.IDChainStore, ID string, paths ...string) *types.Block {
+	payload := &id.PayloadRegisterIdentification{ID: ID}
+	for _, path := range paths {
+		payload.Contents = append(payload.Contents, id.RegisterIdentificationContent{
+			Path:   path,
+			Values: []id.RegisterIdentificationValue{{Proof: path}},
+		})
+	}
+	height := store.GetHeight() + 1
+	block := &types.Block{
+		Header: types.Header{
+			Previous:  store.GetCurrentBlockHash(),
+			Timestamp: params.RegNetGenesisBlock.Header.Timestamp + height*120,
+			Height:    height,
+		},
+		Transactions: []*types.Transaction{{
+			TxType:         id.RegisterIdentification,
+			PayloadVersion: id.RegisterIdentificationVersion1,
+			Payload:        payload,
+		}},
+	}
+	if err := store.SaveBlock(block); err != nil {
+		t.Fatalf("save block %d error: %s", height, err)
+	}
+	return block
+}
+
+func listedPaths(t *testing.T, s *HttpServiceExtend, param util.Params) map[string][]string {
+	result, err := s.ListIdentifications(param)
+	if err != nil {
+		t.Fatal("list identifications error:", err)
+	}
+	paths := make(map[string][]string)
+	for _, item := range result.(*IdentificationListInfo).Identifications {
+		for _, path := range item.Paths {
+			paths[item.Id] = append(paths[item.Id], path.Path)
+		}
+	}
+	return paths
+}
+
+func TestListIdentifications(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const other = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	s, remove := newTestService(t)
+	defer remove()
+
+	saveTestBlock(t, s.store, ID, "kyc/person/identityCard", "social/twitter")
+	saveTestBlock(t, s.store, other, "kyc/person/phone")
+	block := saveTestBlock(t, s.store, ID, "kyc/person/phone")
+
+	if height, err := s.store.GetRegistrationHeight(ID, "kyc/person/phone"); err != nil ||
+		height != block.Header.Height {
+		t.Error("registration height error!")
+	}
+
+	paths := listedPaths(t, s, util.Params{})
+	if len(paths) != 2 || len(paths[ID]) != 3 || len(paths[other]) != 1 {
+		t.Errorf("listed paths %v", paths)
+	}
+
+	paths = listedPaths(t, s, util.Params{"pathprefix": "kyc/"})
+	if len(paths[ID]) != 2 || len(paths[other]) != 1 {
+		t.Errorf("listed paths with a prefix %v", paths)
+	}
+
+	paths = listedPaths(t, s, util.Params{"haspath": "social/twitter"})
+	if len(paths) != 1 || len(paths[ID]) != 1 {
+		t.Errorf("listed paths with a path %v", paths)
+	}
+
+	// Only the paths registered after the height are listed, the IDs left
+	// without a path are left out.
+	paths = listedPaths(t, s, util.Params{"registeredafter": float64(2)})
+	if len(paths) != 1 || len(paths[ID]) != 1 || paths[ID][0] != "kyc/person/phone" {
+		t.Errorf("listed paths registered after 2 %v", paths)
+	}
+
+	// Pages of one ID end with the cursor of the next one.
+	result, err := s.ListIdentifications(util.Params{"limit": float64(1)})
+	if err != nil {
+		t.Fatal("list identifications error:", err)
+	}
+	page := result.(*IdentificationListInfo)
+	if len(page.Identifications) != 1 || page.Next != page.Identifications[0].Id {
+		t.Fatal("first page error!")
+	}
+	result, err = s.ListIdentifications(util.Params{"cursor": page.Next, "limit": float64(1)})
+	if err != nil {
+		t.Fatal("list identifications error:", err)
+	}
+	page = result.(*IdentificationListInfo)
+	if len(page.Identifications) != 1 || page.Next != "" {
+		t.Error("last page error!")
+	}
+}
+
+func TestCachedRollback(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const path = "kyc/person/identityCard"
+
+	s, remove := newTestService(t)
+	defer remove()
+
+	first := saveTestBlock(t, s.store, ID, path)
+	second := saveTestBlock(t, s.store, ID, path)
+
+	load := func() (interface{}, error) {
+		return s.store.GetRegisterIdentificationTx([]byte(ID + path))
+	}
+	resolve := func() string {
+		value, err := s.cached(pathCacheKey(ID, path), load)
+		if err != nil {
+			t.Fatal("resolve error:", err)
+		}
+		txHash, err := common.Uint256FromBytes(value.([]byte))
+		if err != nil {
+			t.Fatal("resolved hash error:", err)
+		}
+		return txHash.String()
+	}
+
+	if resolve() != second.Transactions[0].Hash().String() {
+		t.Fatal("path should resolve to the last registration!")
+	}
+	if resolve() != second.Transactions[0].Hash().String() {
+		t.Fatal("cached path should resolve to the last registration!")
+	}
+
+	// The rolled back registration leaves the cache.
+	if err := s.store.RollbackBlock(second.Hash()); err != nil {
+		t.Fatal("rollback error:", err)
+	}
+	if resolve() != first.Transactions[0].Hash().String() {
+		t.Error("path should resolve to the registration left after the rollback!")
+	}
+}
@@ -1,9 +1,25 @@
 package service
 
+import "github.com/elastos/Elastos.ELA.SideChain/service"
+
+type ExpiryInfo struct {
+	Type    string `json:"type"`
+	Value   uint32 `json:"value"`
+	Expired bool   `json:"expired"`
+}
+
+type EncryptedInfoInfo struct {
+	Recipients []string `json:"recipients"`
+	Envelope   string   `json:"envelope"`
+}
+
 type RegisterIdentificationValueInfo struct {
-	DataHash string `json:"datahash"`
-	Proof    string `json:"proof"`
-	Info     string `json:"info"`
+	DataHash      string             `json:"datahash"`
+	Proof         string             `json:"proof"`
+	Info          string             `json:"info"`
+	Encrypted     bool               `json:"encrypted,omitempty"`
+	EncryptedInfo *EncryptedInfoInfo `json:"encryptedinfo,omitempty"`
+	Expiry        *ExpiryInfo        `json:"expiry,omitempty"`
 }
 
 type RegisterIdentificationContentInfo struct {
@@ -16,3 +32,202 @@ type RegisterIdentificationInfo struct {
 	Sign     string                              `json:"sign"`
 	Contents []RegisterIdentificationContentInfo `json:"contents"`
 }
+
+type RegisterIdentificationBatchInfo struct {
+	Entries []*RegisterIdentificationInfo `json:"entries"`
+}
+
+type ServiceEndpointInfo struct {
+	Type     string `json:"type"`
+	Endpoint string `json:"endpoint"`
+}
+
+type RegisterServiceEndpointInfo struct {
+	Id        string                `json:"id"`
+	Sequence  uint32                `json:"sequence"`
+	Endpoints []ServiceEndpointInfo `json:"endpoints"`
+}
+
+type IdentificationTxInfo struct {
+	*service.TransactionInfo
+	Services []ServiceEndpointInfo `json:"services,omitempty"`
+	Proof    *TransactionProofInfo `json:"proof,omitempty"`
+}
+
+type DIDServiceInfo struct {
+	Id              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+type DIDDocument struct {
+	Context    string           `json:"@context"`
+	Id         string           `json:"id"`
+	Controller string           `json:"controller,omitempty"`
+	Service    []DIDServiceInfo `json:"service,omitempty"`
+}
+
+type TransferIdentificationInfo struct {
+	Id            string `json:"id"`
+	Sequence      uint32 `json:"sequence"`
+	NewController string `json:"newcontroller"`
+}
+
+type ControllerRecordInfo struct {
+	Controller string `json:"controller"`
+	TxId       string `json:"txid"`
+	Height     uint32 `json:"height"`
+}
+
+type IdentificationControllersInfo struct {
+	Id         string                 `json:"id"`
+	Controller string                 `json:"controller"`
+	Sequence   uint32                 `json:"sequence"`
+	History    []ControllerRecordInfo `json:"history"`
+}
+
+type SetRecoveryGuardiansInfo struct {
+	Id        string   `json:"id"`
+	Sequence  uint32   `json:"sequence"`
+	Guardians []string `json:"guardians"`
+	Threshold uint32   `json:"threshold"`
+	Delay     uint32   `json:"delay"`
+}
+
+type RecoverIdentificationInfo struct {
+	Id            string `json:"id"`
+	Sequence      uint32 `json:"sequence"`
+	NewController string `json:"newcontroller"`
+}
+
+type CancelRecoveryInfo struct {
+	Id       string `json:"id"`
+	Sequence uint32 `json:"sequence"`
+}
+
+type PendingRecoveryInfo struct {
+	NewController   string `json:"newcontroller"`
+	TxId            string `json:"txid"`
+	Height          uint32 `json:"height"`
+	EffectiveHeight uint32 `json:"effectiveheight"`
+}
+
+type IdentificationRecoveryInfo struct {
+	Id        string               `json:"id"`
+	Guardians []string             `json:"guardians"`
+	Threshold uint32               `json:"threshold"`
+	Delay     uint32               `json:"delay"`
+	Pending   *PendingRecoveryInfo `json:"pending"`
+}
+
+type AnchorPayloadInfo struct {
+	Hashes []string `json:"hashes"`
+	Memo   string   `json:"memo"`
+}
+
+type MerkleProofInfo struct {
+	Index      uint32   `json:"index"`
+	TxCount    uint32   `json:"txcount"`
+	Branch     []string `json:"branch"`
+	MerkleRoot string   `json:"merkleroot"`
+}
+
+type AnchorInfo struct {
+	Hash string `json:"hash"`
+	Memo string `json:"memo"`
+	*TransactionProofInfo
+}
+
+type TransactionProofInfo struct {
+	TxId      string           `json:"txid"`
+	Tx        string           `json:"tx"`
+	BlockHash string           `json:"blockhash"`
+	Height    uint32           `json:"height"`
+	Time      uint32           `json:"time"`
+	Header    string           `json:"header"`
+	Proof     *MerkleProofInfo `json:"proof"`
+}
+
+type StateRootInfo struct {
+	Height uint32 `json:"height"`
+	Root   string `json:"root"`
+}
+
+type StateLeafInfo struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type StateProofInfo struct {
+	Height   uint32         `json:"height"`
+	Root     string         `json:"root"`
+	Key      string         `json:"key"`
+	Value    string         `json:"value"`
+	Siblings []string       `json:"siblings"`
+	Leaf     *StateLeafInfo `json:"leaf"`
+}
+
+type IdentificationChangeInfo struct {
+	Height uint32 `json:"height"`
+	Type   string `json:"type"`
+	Id     string `json:"id"`
+	Path   string `json:"path,omitempty"`
+	TxId   string `json:"txid"`
+}
+
+type ChangesCursorInfo struct {
+	Height uint32 `json:"height"`
+	Index  uint32 `json:"index"`
+}
+
+type IdentificationChangesInfo struct {
+	Start   uint32                     `json:"start"`
+	End     uint32                     `json:"end"`
+	Changes []IdentificationChangeInfo `json:"changes"`
+	Next    *ChangesCursorInfo         `json:"next"`
+}
+
+type IdentificationPathInfo struct {
+	Path string `json:"path"`
+	TxId string `json:"txid"`
+}
+
+type IdentificationListItemInfo struct {
+	Id    string                   `json:"id"`
+	Paths []IdentificationPathInfo `json:"paths"`
+}
+
+type IdentificationListInfo struct {
+	Identifications []IdentificationListItemInfo `json:"identifications"`
+	Next            string                       `json:"next"`
+}
+
+type DailyRegistrationsInfo struct {
+	Date          string `json:"date"`
+	Registrations uint64 `json:"registrations"`
+}
+
+type IdentificationStatsInfo struct {
+	TotalIDs      uint64                   `json:"totalids"`
+	TotalPaths    uint64                   `json:"totalpaths"`
+	Registrations uint64                   `json:"registrations"`
+	Revocations   uint64                   `json:"revocations"`
+	Daily         []DailyRegistrationsInfo `json:"daily"`
+}
+
+type ResolveCacheInfo struct {
+	Capacity int    `json:"capacity"`
+	Size     int    `json:"size"`
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+}
+
+type AddressIdentificationInfo struct {
+	Id   string `json:"id"`
+	TxId string `json:"txid"`
+}
+
+type AddressIdentificationsInfo struct {
+	Identifications []AddressIdentificationInfo `json:"identifications"`
+	Next            string                      `json:"next"`
+}
@@ -0,0 +1,388 @@
+package types
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+// EncryptedInfoPrefix marks an Info string that carries an encrypted envelope
+// instead of plain text, the hex encoded envelope follows the prefix.
+const EncryptedInfoPrefix = "ecies:"
+
+const EncryptedInfoVersion = 0x00
+
+// MaxInfoRecipients is the maximum number of recipients of an encrypted Info.
+const MaxInfoRecipients = 16
+
+const (
+	publicKeySize     = 33
+	contentKeySize    = 32
+	maxCipherTextSize = 1024 * 1024
+)
+
+// EncryptedInfoRecipient holds the content key of an envelope wrapped for
+// one recipient public key.
+type EncryptedInfoRecipient struct {
+	PublicKey  []byte
+	Nonce      []byte
+	WrappedKey []byte
+}
+
+// EncryptedInfo is an ECIES envelope of an Info payload. The payload is
+// encrypted with AES-256-GCM under a random content key, and the content key is
+// wrapped for every recipient with a key derived from ECDH between the
+// ephemeral key and the recipient public key on the P-256 curve.
+type EncryptedInfo struct {
+	Version      byte
+	EphemeralKey []byte
+	Recipients   []EncryptedInfoRecipient
+	Nonce        []byte
+	Ciphertext   []byte
+}
+
+// IsEncryptedInfo returns whether the Info string carries an encrypted
+// envelope.
+func IsEncryptedInfo(info string) bool {
+	return strings.HasPrefix(info, EncryptedInfoPrefix)
+}
+
+// ParseEncryptedInfo decodes the encrypted envelope of an Info string.
+func ParseEncryptedInfo(info string) (*EncryptedInfo, error) {
+	if !IsEncryptedInfo(info) {
+		return nil, errors.New("[EncryptedInfo], info is not encrypted.")
+	}
+
+	data, err := hex.DecodeString(info[len(EncryptedInfoPrefix):])
+	if err != nil {
+		return nil, errors.New("[EncryptedInfo], invalid envelope encoding.")
+	}
+
+	r := bytes.NewReader(data)
+	envelope := new(EncryptedInfo)
+	if err := envelope.Deserialize(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("[EncryptedInfo], unexpected data after the envelope.")
+	}
+
+	return envelope, nil
+}
+
+// SealInfo encrypts the plain text for the given compressed public keys and
+// returns the Info string carrying the envelope.
+func SealInfo(plainText []byte, recipients [][]byte) (string, error) {
+	if len(recipients) == 0 || len(recipients) > MaxInfoRecipients {
+		return "", errors.New("[EncryptedInfo], invalid recipients count.")
+	}
+
+	curve := elliptic.P256()
+	ephemeral, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	contentKey := make([]byte, contentKeySize)
+	if _, err := io.ReadFull(rand.Reader, contentKey); err != nil {
+		return "", err
+	}
+
+	envelope := &EncryptedInfo{
+		Version:      EncryptedInfoVersion,
+		EphemeralKey: marshalCompressedPoint(curve, ephemeral.X, ephemeral.Y),
+	}
+
+	for _, publicKey := range recipients {
+		x, y := unmarshalCompressedPoint(curve, publicKey)
+		if x == nil {
+			return "", errors.New("[EncryptedInfo], invalid recipient public key.")
+		}
+
+		sx, _ := curve.ScalarMult(x, y, ephemeral.D.Bytes())
+		nonce, wrappedKey, err := seal(deriveKey(sx, envelope.EphemeralKey, publicKey), contentKey)
+		if err != nil {
+			return "", err
+		}
+
+		envelope.Recipients = append(envelope.Recipients, EncryptedInfoRecipient{
+			PublicKey:  publicKey,
+			Nonce:      nonce,
+			WrappedKey: wrappedKey,
+		})
+	}
+
+	envelope.Nonce, envelope.Ciphertext, err = seal(contentKey, plainText)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := envelope.Serialize(buf); err != nil {
+		return "", err
+	}
+
+	return EncryptedInfoPrefix + hex.EncodeToString(buf.Bytes()), nil
+}
+
+// OpenInfo decrypts the envelope carried by the Info string with the private
+// key of one of its recipients.
+func OpenInfo(info string, privateKey []byte) ([]byte, error) {
+	envelope, err := ParseEncryptedInfo(info)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := elliptic.P256()
+	px, py := curve.ScalarBaseMult(privateKey)
+	publicKey := marshalCompressedPoint(curve, px, py)
+
+	var recipient *EncryptedInfoRecipient
+	for i := range envelope.Recipients {
+		if bytes.Equal(envelope.Recipients[i].PublicKey, publicKey) {
+			recipient = &envelope.Recipients[i]
+			break
+		}
+	}
+	if recipient == nil {
+		return nil, errors.New("[EncryptedInfo], not a recipient of the envelope.")
+	}
+
+	x, y := unmarshalCompressedPoint(curve, envelope.EphemeralKey)
+	if x == nil {
+		return nil, errors.New("[EncryptedInfo], invalid ephemeral key.")
+	}
+
+	sx, _ := curve.ScalarMult(x, y, privateKey)
+	contentKey, err := open(deriveKey(sx, envelope.EphemeralKey, publicKey),
+		recipient.Nonce, recipient.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(contentKey, envelope.Nonce, envelope.Ciphertext)
+}
+
+// RecipientKeys returns the public keys the envelope is addressed to.
+func (e *EncryptedInfo) RecipientKeys() [][]byte {
+	keys := make([][]byte, 0, len(e.Recipients))
+	for _, recipient := range e.Recipients {
+		keys = append(keys, recipient.PublicKey)
+	}
+	return keys
+}
+
+func (e *EncryptedInfo) Serialize(w io.Writer) error {
+	if err := common.WriteUint8(w, e.Version); err != nil {
+		return errors.New("[EncryptedInfo], Version serialize failed.")
+	}
+
+	if err := common.WriteVarBytes(w, e.EphemeralKey); err != nil {
+		return errors.New("[EncryptedInfo], EphemeralKey serialize failed.")
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(e.Recipients))); err != nil {
+		return errors.New("[EncryptedInfo], Recipients size serialize failed.")
+	}
+
+	for _, recipient := range e.Recipients {
+		if err := common.WriteVarBytes(w, recipient.PublicKey); err != nil {
+			return errors.New("[EncryptedInfo], recipient PublicKey serialize failed.")
+		}
+
+		if err := common.WriteVarBytes(w, recipient.Nonce); err != nil {
+			return errors.New("[EncryptedInfo], recipient Nonce serialize failed.")
+		}
+
+		if err := common.WriteVarBytes(w, recipient.WrappedKey); err != nil {
+			return errors.New("[EncryptedInfo], recipient WrappedKey serialize failed.")
+		}
+	}
+
+	if err := common.WriteVarBytes(w, e.Nonce); err != nil {
+		return errors.New("[EncryptedInfo], Nonce serialize failed.")
+	}
+
+	if err := common.WriteVarBytes(w, e.Ciphertext); err != nil {
+		return errors.New("[EncryptedInfo], Ciphertext serialize failed.")
+	}
+
+	return nil
+}
+
+func (e *EncryptedInfo) Deserialize(r io.Reader) error {
+	var err error
+	e.Version, err = common.ReadUint8(r)
+	if err != nil {
+		return errors.New("[EncryptedInfo], Version deserialize failed.")
+	}
+	if e.Version != EncryptedInfoVersion {
+		return errors.New("[EncryptedInfo], unknown envelope version.")
+	}
+
+	e.EphemeralKey, err = common.ReadVarBytes(r, publicKeySize, "EncryptedInfo ephemeral key")
+	if err != nil {
+		return errors.New("[EncryptedInfo], EphemeralKey deserialize failed.")
+	}
+	if !isCurvePoint(e.EphemeralKey) {
+		return errors.New("[EncryptedInfo], invalid ephemeral key.")
+	}
+
+	size, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[EncryptedInfo], Recipients size deserialize failed.")
+	}
+	if size == 0 || size > MaxInfoRecipients {
+		return errors.New("[EncryptedInfo], invalid recipients count.")
+	}
+
+	e.Recipients = make([]EncryptedInfoRecipient, size)
+	for i := uint64(0); i < size; i++ {
+		recipient := EncryptedInfoRecipient{}
+		recipient.PublicKey, err = common.ReadVarBytes(r, publicKeySize, "EncryptedInfo recipient public key")
+		if err != nil {
+			return errors.New("[EncryptedInfo], recipient PublicKey deserialize failed.")
+		}
+		if !isCurvePoint(recipient.PublicKey) {
+			return errors.New("[EncryptedInfo], invalid recipient public key.")
+		}
+
+		recipient.Nonce, err = common.ReadVarBytes(r, MaxSignDataSize, "EncryptedInfo recipient nonce")
+		if err != nil {
+			return errors.New("[EncryptedInfo], recipient Nonce deserialize failed.")
+		}
+
+		recipient.WrappedKey, err = common.ReadVarBytes(r, MaxSignDataSize, "EncryptedInfo recipient wrapped key")
+		if err != nil {
+			return errors.New("[EncryptedInfo], recipient WrappedKey deserialize failed.")
+		}
+		e.Recipients[i] = recipient
+	}
+
+	e.Nonce, err = common.ReadVarBytes(r, MaxSignDataSize, "EncryptedInfo nonce")
+	if err != nil {
+		return errors.New("[EncryptedInfo], Nonce deserialize failed.")
+	}
+
+	e.Ciphertext, err = common.ReadVarBytes(r, maxCipherTextSize, "EncryptedInfo cipher text")
+	if err != nil {
+		return errors.New("[EncryptedInfo], Ciphertext deserialize failed.")
+	}
+
+	return nil
+}
+
+// isCurvePoint returns whether the key is a compressed point of the P-256
+// curve.
+func isCurvePoint(key []byte) bool {
+	x, _ := unmarshalCompressedPoint(elliptic.P256(), key)
+	return x != nil
+}
+
+// marshalCompressedPoint encodes the point of the curve in the compressed
+// form of SEC 1.
+func marshalCompressedPoint(curve elliptic.Curve, x, y *big.Int) []byte {
+	byteLen := (curve.Params().BitSize + 7) / 8
+	compressed := make([]byte, 1+byteLen)
+	compressed[0] = byte(2 + y.Bit(0))
+	xBytes := x.Bytes()
+	copy(compressed[1+byteLen-len(xBytes):], xBytes)
+	return compressed
+}
+
+// unmarshalCompressedPoint decodes a point of the curve in the compressed
+// form of SEC 1, x is nil if the data is not a point of the curve.
+func unmarshalCompressedPoint(curve elliptic.Curve, data []byte) (x, y *big.Int) {
+	params := curve.Params()
+	byteLen := (params.BitSize + 7) / 8
+	if len(data) != 1+byteLen || (data[0] != 2 && data[0] != 3) {
+		return nil, nil
+	}
+	x = new(big.Int).SetBytes(data[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil
+	}
+
+	// y² = x³ - 3x + b
+	y = new(big.Int).Mul(x, x)
+	y.Mul(y, x)
+	threeX := new(big.Int).Lsh(x, 1)
+	threeX.Add(threeX, x)
+	y.Sub(y, threeX)
+	y.Add(y, params.B)
+	y.Mod(y, params.P)
+	if y.ModSqrt(y, params.P) == nil {
+		return nil, nil
+	}
+	if byte(y.Bit(0)) != data[0]&1 {
+		y.Neg(y).Mod(y, params.P)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, nil
+	}
+	return x, y
+}
+
+// deriveKey derives the key wrapping the content key from the ECDH shared
+// secret and the public keys of both sides.
+func deriveKey(sharedX *big.Int, ephemeralKey, publicKey []byte) []byte {
+	secret := make([]byte, 32)
+	sx := sharedX.Bytes()
+	copy(secret[len(secret)-len(sx):], sx)
+
+	h := sha256.New()
+	h.Write(secret)
+	h.Write(ephemeralKey)
+	h.Write(publicKey)
+	return h.Sum(nil)
+}
+
+func seal(key, plainText []byte) ([]byte, []byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+
+	return nonce, aead.Seal(nil, nonce, plainText, nil), nil
+}
+
+func open(key, nonce, cipherText []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("[EncryptedInfo], invalid nonce size.")
+	}
+
+	plainText, err := aead.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return nil, errors.New("[EncryptedInfo], decrypt failed.")
+	}
+
+	return plainText, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSealInfo(t *testing.T) {
+	curve := elliptic.P256()
+	alice, _ := ecdsa.GenerateKey(curve, rand.Reader)
+	bob, _ := ecdsa.GenerateKey(curve, rand.Reader)
+	eve, _ := ecdsa.GenerateKey(curve, rand.Reader)
+
+	recipients := [][]byte{
+		marshalCompressedPoint(curve, alice.X, alice.Y),
+		marshalCompressedPoint(curve, bob.X, bob.Y),
+	}
+
+	info, err := SealInfo([]byte("information for register"), recipients)
+	if err != nil {
+		t.Fatal("seal info error:", err)
+	}
+
+	if !IsEncryptedInfo(info) || IsEncryptedInfo("information for register") {
+		t.Error("encrypted info detection error!")
+	}
+
+	envelope, err := ParseEncryptedInfo(info)
+	if err != nil {
+		t.Fatal("parse encrypted info error:", err)
+	}
+
+	keys := envelope.RecipientKeys()
+	if len(keys) != 2 || !bytes.Equal(keys[0], recipients[0]) || !bytes.Equal(keys[1], recipients[1]) {
+		t.Error("encrypted info recipients error!")
+	}
+
+	for _, key := range []*ecdsa.PrivateKey{alice, bob} {
+		plainText, err := OpenInfo(info, key.D.Bytes())
+		if err != nil {
+			t.Error("open info error:", err)
+		}
+		if string(plainText) != "information for register" {
+			t.Error("open info plain text error!")
+		}
+	}
+
+	if _, err := OpenInfo(info, eve.D.Bytes()); err == nil {
+		t.Error("open info by non recipient should fail!")
+	}
+
+	if _, err := SealInfo([]byte("information for register"), nil); err == nil {
+		t.Error("seal info without recipients should fail!")
+	}
+}
+
+func TestParseEncryptedInfo(t *testing.T) {
+	curve := elliptic.P256()
+	alice, _ := ecdsa.GenerateKey(curve, rand.Reader)
+	recipients := [][]byte{marshalCompressedPoint(curve, alice.X, alice.Y)}
+
+	info, err := SealInfo([]byte("information for register"), recipients)
+	if err != nil {
+		t.Fatal("seal info error:", err)
+	}
+	envelope, err := ParseEncryptedInfo(info)
+	if err != nil {
+		t.Fatal("parse encrypted info error:", err)
+	}
+
+	if _, err := ParseEncryptedInfo(info + "00"); err == nil {
+		t.Error("parse encrypted info with trailing data should fail!")
+	}
+
+	// An x coordinate without a point on the curve.
+	offCurve := make([]byte, 33)
+	offCurve[0] = 0x02
+	offCurve[32] = 0x01
+	if x, _ := unmarshalCompressedPoint(curve, offCurve); x != nil {
+		t.Fatal("test key is on the curve")
+	}
+
+	for _, tamper := range []func(e *EncryptedInfo){
+		func(e *EncryptedInfo) { e.EphemeralKey = offCurve },
+		func(e *EncryptedInfo) { e.Recipients[0].PublicKey = offCurve },
+	} {
+		tampered := *envelope
+		tampered.Recipients = append([]EncryptedInfoRecipient(nil), envelope.Recipients...)
+		tamper(&tampered)
+
+		buf := new(bytes.Buffer)
+		if err := tampered.Serialize(buf); err != nil {
+			t.Fatal("serialize encrypted info error:", err)
+		}
+		if _, err := ParseEncryptedInfo(EncryptedInfoPrefix + hex.EncodeToString(buf.Bytes())); err == nil {
+			t.Error("parse encrypted info with a key off the curve should fail!")
+		}
+	}
+}
+
+func TestCompressedPoint(t *testing.T) {
+	curve := elliptic.P256()
+	for i := 0; i < 20; i++ {
+		key, _ := ecdsa.GenerateKey(curve, rand.Reader)
+		compressed := marshalCompressedPoint(curve, key.X, key.Y)
+		if len(compressed) != 33 || compressed[0] != byte(2+key.Y.Bit(0)) {
+			t.Fatalf("compressed point %x", compressed)
+		}
+		x, y := unmarshalCompressedPoint(curve, compressed)
+		if x == nil || x.Cmp(key.X) != 0 || y.Cmp(key.Y) != 0 {
+			t.Fatal("compressed point round trip error!")
+		}
+	}
+
+	// The x coordinate must be below the field prime.
+	beyond := append([]byte{0x02}, curve.Params().P.Bytes()...)
+	if x, _ := unmarshalCompressedPoint(curve, beyond); x != nil {
+		t.Error("x coordinate beyond the field should be rejected!")
+	}
+	if x, _ := unmarshalCompressedPoint(curve, make([]byte, 33)); x != nil {
+		t.Error("unknown prefix should be rejected!")
+	}
+}
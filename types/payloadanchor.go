@@ -0,0 +1,89 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+const Anchor = 0x10
+const AnchorVersion = 0x00
+
+const (
+	// MaxAnchorHashes is the maximum number of hashes anchored by a
+	// transaction.
+	MaxAnchorHashes = 1024
+
+	// MaxAnchorMemoSize is the maximum size of the memo of an anchoring
+	// transaction.
+	MaxAnchorMemoSize = 256
+)
+
+// PayloadAnchor anchors document hashes on the chain as a proof of their
+// existence at the time of the block.
+type PayloadAnchor struct {
+	Hashes []common.Uint256
+	Memo   string
+}
+
+func (p *PayloadAnchor) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadAnchor) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarUint(w, uint64(len(p.Hashes))); err != nil {
+		return errors.New("[Anchor], Hashes size serialize failed.")
+	}
+
+	for _, hash := range p.Hashes {
+		if err := hash.Serialize(w); err != nil {
+			return errors.New("[Anchor], Hash serialize failed.")
+		}
+	}
+
+	if err := common.WriteVarString(w, p.Memo); err != nil {
+		return errors.New("[Anchor], Memo serialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadAnchor) Deserialize(r io.Reader, version byte) error {
+	size, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[Anchor], Hashes size deserialize failed.")
+	}
+	if size > MaxAnchorHashes {
+		return errors.New("[Anchor], too many hashes.")
+	}
+
+	p.Hashes = make([]common.Uint256, size)
+	hashes := make(map[common.Uint256]struct{}, size)
+	for i := uint64(0); i < size; i++ {
+		if err := p.Hashes[i].Deserialize(r); err != nil {
+			return errors.New("[Anchor], Hash deserialize failed.")
+		}
+		if _, ok := hashes[p.Hashes[i]]; ok {
+			return errors.New("[Anchor], duplicate hash.")
+		}
+		hashes[p.Hashes[i]] = struct{}{}
+	}
+
+	p.Memo, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[Anchor], Memo deserialize failed.")
+	}
+	if len(p.Memo) > MaxAnchorMemoSize {
+		return errors.New("[Anchor], memo is too long.")
+	}
+
+	return nil
+}
+
+func (p *PayloadAnchor) GetData() []byte {
+	return p.Data(AnchorVersion)
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+func TestPayloadAnchor_Deserialize(t *testing.T) {
+	payload := &PayloadAnchor{
+		Hashes: []common.Uint256{{1}, {2}},
+		Memo:   "contract v2",
+	}
+
+	buf := new(bytes.Buffer)
+	if err := payload.Serialize(buf, AnchorVersion); err != nil {
+		t.Fatal("anchor serialize error:", err)
+	}
+
+	payload2 := PayloadAnchor{}
+	if err := payload2.Deserialize(bytes.NewReader(buf.Bytes()), AnchorVersion); err != nil {
+		t.Fatal("anchor deserialize error:", err)
+	}
+	if len(payload2.Hashes) != 2 || payload2.Hashes[0] != payload.Hashes[0] ||
+		payload2.Hashes[1] != payload.Hashes[1] || payload2.Memo != payload.Memo {
+		t.Error("anchor deserialize error!")
+	}
+
+	hashes := func(count int) []common.Uint256 {
+		hashes := make([]common.Uint256, count)
+		for i := range hashes {
+			hashes[i] = common.Uint256{byte(i), byte(i >> 8), 1}
+		}
+		return hashes
+	}
+	for _, invalid := range []*PayloadAnchor{
+		{Hashes: hashes(MaxAnchorHashes + 1)},
+		{Hashes: hashes(1), Memo: strings.Repeat("m", MaxAnchorMemoSize+1)},
+		{Hashes: []common.Uint256{{1}, {2}, {1}}},
+	} {
+		buf := new(bytes.Buffer)
+		if err := invalid.Serialize(buf, AnchorVersion); err != nil {
+			t.Fatal("anchor serialize error:", err)
+		}
+		if err := new(PayloadAnchor).Deserialize(bytes.NewReader(buf.Bytes()), AnchorVersion); err == nil {
+			t.Errorf("anchor of %d hashes and a memo of %d bytes should be rejected!",
+				len(invalid.Hashes), len(invalid.Memo))
+		}
+	}
+
+	// The limits themselves are accepted.
+	limits := &PayloadAnchor{Hashes: hashes(MaxAnchorHashes), Memo: strings.Repeat("m", MaxAnchorMemoSize)}
+	buf.Reset()
+	if err := limits.Serialize(buf, AnchorVersion); err != nil {
+		t.Fatal("anchor serialize error:", err)
+	}
+	if err := new(PayloadAnchor).Deserialize(bytes.NewReader(buf.Bytes()), AnchorVersion); err != nil {
+		t.Error("anchor at the limits deserialize error:", err)
+	}
+}
@@ -0,0 +1,225 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+const SetRecoveryGuardians = 0x0c
+const SetRecoveryGuardiansVersion = 0x00
+
+const RecoverIdentification = 0x0d
+const RecoverIdentificationVersion = 0x00
+
+const CancelRecovery = 0x0e
+const CancelRecoveryVersion = 0x00
+
+const (
+	// MaxRecoveryGuardians is the maximum number of guardians of an ID.
+	MaxRecoveryGuardians = 16
+
+	// MinRecoveryDelay is the minimum number of blocks between a recovery
+	// and the change of the controller, about one day of blocks.
+	MinRecoveryDelay = 720
+
+	// MaxRecoveryDelay is the maximum number of blocks between a recovery
+	// and the change of the controller, about one year of blocks, so the
+	// effective height of a recovery does not overflow.
+	MaxRecoveryDelay = 365 * 720
+)
+
+// PayloadSetRecoveryGuardians sets the guardian IDs which are able to recover
+// the control of an ID, an empty guardian list disables recovery.
+//
+// The recovery payloads carry the sequence number of the ID they apply to, so
+// an old guardian set, recovery or cancellation can not be replayed.
+type PayloadSetRecoveryGuardians struct {
+	ID        string
+	Sequence  uint32
+	Guardians []string
+	Threshold uint32
+	Delay     uint32
+}
+
+// PayloadRecoverIdentification starts the recovery of an ID to a new
+// controller. The transaction must be signed by at least the threshold of
+// the guardians, and the controller changes after the delay set with the
+// guardians unless the current controller cancels the recovery.
+type PayloadRecoverIdentification struct {
+	ID            string
+	Sequence      uint32
+	NewController string
+}
+
+// PayloadCancelRecovery cancels the pending recovery of an ID.
+type PayloadCancelRecovery struct {
+	ID       string
+	Sequence uint32
+}
+
+func (p *PayloadSetRecoveryGuardians) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadSetRecoveryGuardians) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarString(w, p.ID); err != nil {
+		return errors.New("[SetRecoveryGuardians], ID serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Sequence); err != nil {
+		return errors.New("[SetRecoveryGuardians], Sequence serialize failed.")
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Guardians))); err != nil {
+		return errors.New("[SetRecoveryGuardians], Guardians size serialize failed.")
+	}
+
+	for _, guardian := range p.Guardians {
+		if err := common.WriteVarString(w, guardian); err != nil {
+			return errors.New("[SetRecoveryGuardians], Guardian serialize failed.")
+		}
+	}
+
+	if err := common.WriteUint32(w, p.Threshold); err != nil {
+		return errors.New("[SetRecoveryGuardians], Threshold serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Delay); err != nil {
+		return errors.New("[SetRecoveryGuardians], Delay serialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadSetRecoveryGuardians) Deserialize(r io.Reader, version byte) error {
+	var err error
+	p.ID, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[SetRecoveryGuardians], ID deserialize failed.")
+	}
+
+	p.Sequence, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[SetRecoveryGuardians], Sequence deserialize failed.")
+	}
+
+	size, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[SetRecoveryGuardians], Guardians size deserialize failed.")
+	}
+	if size > MaxRecoveryGuardians {
+		return errors.New("[SetRecoveryGuardians], too many guardians.")
+	}
+
+	p.Guardians = make([]string, size)
+	for i := uint64(0); i < size; i++ {
+		p.Guardians[i], err = common.ReadVarString(r)
+		if err != nil {
+			return errors.New("[SetRecoveryGuardians], Guardian deserialize failed.")
+		}
+	}
+
+	p.Threshold, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[SetRecoveryGuardians], Threshold deserialize failed.")
+	}
+
+	p.Delay, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[SetRecoveryGuardians], Delay deserialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadSetRecoveryGuardians) GetData() []byte {
+	return signedData(SetRecoveryGuardians, p.Data(SetRecoveryGuardiansVersion))
+}
+
+func (p *PayloadRecoverIdentification) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadRecoverIdentification) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarString(w, p.ID); err != nil {
+		return errors.New("[RecoverIdentification], ID serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Sequence); err != nil {
+		return errors.New("[RecoverIdentification], Sequence serialize failed.")
+	}
+
+	if err := common.WriteVarString(w, p.NewController); err != nil {
+		return errors.New("[RecoverIdentification], NewController serialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadRecoverIdentification) Deserialize(r io.Reader, version byte) error {
+	var err error
+	p.ID, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[RecoverIdentification], ID deserialize failed.")
+	}
+
+	p.Sequence, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[RecoverIdentification], Sequence deserialize failed.")
+	}
+
+	p.NewController, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[RecoverIdentification], NewController deserialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadRecoverIdentification) GetData() []byte {
+	return signedData(RecoverIdentification, p.Data(RecoverIdentificationVersion))
+}
+
+func (p *PayloadCancelRecovery) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadCancelRecovery) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarString(w, p.ID); err != nil {
+		return errors.New("[CancelRecovery], ID serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Sequence); err != nil {
+		return errors.New("[CancelRecovery], Sequence serialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadCancelRecovery) Deserialize(r io.Reader, version byte) error {
+	var err error
+	p.ID, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[CancelRecovery], ID deserialize failed.")
+	}
+
+	p.Sequence, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[CancelRecovery], Sequence deserialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadCancelRecovery) GetData() []byte {
+	return signedData(CancelRecovery, p.Data(CancelRecoveryVersion))
+}
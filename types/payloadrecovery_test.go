@@ -0,0 +1,83 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPayloadRecovery_Deserialize(t *testing.T) {
+	guardians := &PayloadSetRecoveryGuardians{
+		ID:        "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+		Sequence:  2,
+		Guardians: []string{"igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2", "iZEKTuFUKhSibvpt3mDoBUrUdtoP8p2kTQ"},
+		Threshold: 2,
+		Delay:     MinRecoveryDelay,
+	}
+	buf := new(bytes.Buffer)
+	if err := guardians.Serialize(buf, SetRecoveryGuardiansVersion); err != nil {
+		t.Fatal("guardians serialize error:", err)
+	}
+	guardians2 := PayloadSetRecoveryGuardians{}
+	if err := guardians2.Deserialize(bytes.NewReader(buf.Bytes()), SetRecoveryGuardiansVersion); err != nil {
+		t.Fatal("guardians deserialize error:", err)
+	}
+	if guardians2.ID != guardians.ID || guardians2.Sequence != 2 || len(guardians2.Guardians) != 2 ||
+		guardians2.Threshold != 2 || guardians2.Delay != MinRecoveryDelay {
+		t.Error("guardians deserialize error!")
+	}
+
+	recovery := &PayloadRecoverIdentification{
+		ID:            "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+		Sequence:      3,
+		NewController: "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2",
+	}
+	buf = new(bytes.Buffer)
+	if err := recovery.Serialize(buf, RecoverIdentificationVersion); err != nil {
+		t.Fatal("recovery serialize error:", err)
+	}
+	recovery2 := PayloadRecoverIdentification{}
+	if err := recovery2.Deserialize(bytes.NewReader(buf.Bytes()), RecoverIdentificationVersion); err != nil {
+		t.Fatal("recovery deserialize error:", err)
+	}
+	if recovery2 != *recovery {
+		t.Error("recovery deserialize error!")
+	}
+
+	cancel := &PayloadCancelRecovery{ID: "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6", Sequence: 4}
+	buf = new(bytes.Buffer)
+	if err := cancel.Serialize(buf, CancelRecoveryVersion); err != nil {
+		t.Fatal("cancel serialize error:", err)
+	}
+	cancel2 := PayloadCancelRecovery{}
+	if err := cancel2.Deserialize(bytes.NewReader(buf.Bytes()), CancelRecoveryVersion); err != nil {
+		t.Fatal("cancel deserialize error:", err)
+	}
+	if cancel2 != *cancel {
+		t.Error("cancel deserialize error!")
+	}
+
+	// A cancellation signed for an earlier recovery does not verify for a
+	// later one.
+	replayed := *cancel
+	replayed.Sequence = 6
+	if bytes.Equal(replayed.GetData(), cancel.GetData()) {
+		t.Error("cancel signed data does not cover the sequence!")
+	}
+}
+
+func TestPayloadRecovery_GetData(t *testing.T) {
+	const ID = "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6"
+	const controller = "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2"
+
+	// The payloads of the same ID and sequence number sign data of their
+	// own.
+	recovery := (&PayloadRecoverIdentification{ID: ID, Sequence: 3, NewController: controller}).GetData()
+	transfer := (&PayloadTransferIdentification{ID: ID, Sequence: 3, NewController: controller}).GetData()
+	cancel := (&PayloadCancelRecovery{ID: ID, Sequence: 3}).GetData()
+	if bytes.Equal(recovery, transfer) {
+		t.Error("recovery signed data is the transfer signed data!")
+	}
+	if bytes.HasPrefix(recovery, cancel) || bytes.HasPrefix(transfer, cancel) {
+		t.Error("cancel signed data is a prefix of another signed data!")
+	}
+}
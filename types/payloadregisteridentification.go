@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"errors"
 	"io"
 
@@ -12,10 +13,37 @@ const RegisterIdentification = 0x09
 const RegisterIdentificationVersion = 0x00
 const MaxSignDataSize = 1000
 
+// RegisterIdentificationVersion1 adds an optional expiry to every
+// identification value.
+const RegisterIdentificationVersion1 = 0x01
+
+// Expiry types of an identification value.
+const (
+	// ExpiryNone means the value never expires.
+	ExpiryNone byte = 0x00
+
+	// ExpiryHeight means the value expires at the block height in Expiry.
+	ExpiryHeight byte = 0x01
+
+	// ExpiryTimestamp means the value expires at the unix time in Expiry.
+	ExpiryTimestamp byte = 0x02
+)
+
+const (
+	// MaxExpiryHeight is the highest expiry height, about one hundred years
+	// of blocks.
+	MaxExpiryHeight = 100 * 365 * 720
+
+	// MaxExpiryTimestamp is the highest expiry unix time, 2100-01-01.
+	MaxExpiryTimestamp = 4102444800
+)
+
 type RegisterIdentificationValue struct {
-	DataHash common.Uint256
-	Proof    string
-	Info     string
+	DataHash   common.Uint256
+	Proof      string
+	Info       string
+	ExpiryType byte
+	Expiry     uint32
 }
 
 type RegisterIdentificationContent struct {
@@ -31,7 +59,7 @@ type PayloadRegisterIdentification struct {
 
 func (p *PayloadRegisterIdentification) Data(version byte) []byte {
 	buf := new(bytes.Buffer)
-	p.Serialize(buf, RegisterIdentificationVersion)
+	p.Serialize(buf, version)
 	return buf.Bytes()
 }
 
@@ -90,7 +118,21 @@ func (p *PayloadRegisterIdentification) Deserialize(r io.Reader, version byte) e
 }
 
 func (p *PayloadRegisterIdentification) GetData() []byte {
-	return p.Data(RegisterIdentificationVersion)
+	return p.Data(p.Version())
+}
+
+// Version returns the lowest payload version that is able to carry all the
+// values of the payload, so the signed data of payloads without expiry stays
+// the same as before version 1.
+func (p *PayloadRegisterIdentification) Version() byte {
+	for _, content := range p.Contents {
+		for _, value := range content.Values {
+			if value.ExpiryType != ExpiryNone {
+				return RegisterIdentificationVersion1
+			}
+		}
+	}
+	return RegisterIdentificationVersion
 }
 
 func (a *RegisterIdentificationContent) Serialize(w io.Writer, version byte) error {
@@ -111,6 +153,15 @@ func (a *RegisterIdentificationContent) Serialize(w io.Writer, version byte) err
 	return nil
 }
 
+// Hash returns the double SHA256 of the content serialized in the payload
+// version of the transaction registering it.
+func (a *RegisterIdentificationContent) Hash(version byte) common.Uint256 {
+	buf := new(bytes.Buffer)
+	a.Serialize(buf, version)
+	once := sha256.Sum256(buf.Bytes())
+	return common.Uint256(sha256.Sum256(once[:]))
+}
+
 func (a *RegisterIdentificationContent) Deserialize(r io.Reader, version byte) error {
 	path, err := common.ReadVarString(r)
 	if err != nil {
@@ -148,6 +199,16 @@ func (a *RegisterIdentificationValue) Serialize(w io.Writer, version byte) error
 		return errors.New("[RegisterIdentificationValue], Info serialize failed.")
 	}
 
+	if version >= RegisterIdentificationVersion1 {
+		if err := common.WriteUint8(w, a.ExpiryType); err != nil {
+			return errors.New("[RegisterIdentificationValue], ExpiryType serialize failed.")
+		}
+
+		if err := common.WriteUint32(w, a.Expiry); err != nil {
+			return errors.New("[RegisterIdentificationValue], Expiry serialize failed.")
+		}
+	}
+
 	return nil
 }
 
@@ -168,5 +229,29 @@ func (a *RegisterIdentificationValue) Deserialize(r io.Reader, version byte) err
 	}
 	a.Info = info
 
+	if version >= RegisterIdentificationVersion1 {
+		a.ExpiryType, err = common.ReadUint8(r)
+		if err != nil {
+			return errors.New("[RegisterIdentificationValue], ExpiryType deserialize failed.")
+		}
+
+		a.Expiry, err = common.ReadUint32(r)
+		if err != nil {
+			return errors.New("[RegisterIdentificationValue], Expiry deserialize failed.")
+		}
+	}
+
 	return nil
 }
+
+// IsExpired returns whether the value has expired at the given block height
+// and block timestamp.
+func (a *RegisterIdentificationValue) IsExpired(height, timestamp uint32) bool {
+	switch a.ExpiryType {
+	case ExpiryHeight:
+		return height >= a.Expiry
+	case ExpiryTimestamp:
+		return timestamp >= a.Expiry
+	}
+	return false
+}
@@ -63,3 +63,119 @@ func TestPayloadRegisterIdentification_Deserialize(t *testing.T) {
 		t.Error("ID content values proof deserialize error!")
 	}
 }
+
+func TestRegisterIdentificationValue_Expiry(t *testing.T) {
+	payload := &PayloadRegisterIdentification{
+		ID:   "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+		Sign: []byte{1, 1, 1},
+		Contents: []RegisterIdentificationContent{
+			RegisterIdentificationContent{
+				Path: "kyc/person/identityCard",
+				Values: []RegisterIdentificationValue{RegisterIdentificationValue{
+					DataHash:   common.Uint256{2, 2, 2},
+					Proof:      "testproof1",
+					ExpiryType: ExpiryHeight,
+					Expiry:     1000,
+				}}},
+			RegisterIdentificationContent{
+				Path: "kyc/person/phone",
+				Values: []RegisterIdentificationValue{RegisterIdentificationValue{
+					DataHash:   common.Uint256{3, 3, 3},
+					Proof:      "testproof2",
+					ExpiryType: ExpiryTimestamp,
+					Expiry:     1539155763,
+				}}},
+		},
+	}
+
+	if payload.Version() != RegisterIdentificationVersion1 {
+		t.Error("ID payload with expiry should require version 1!")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := payload.Serialize(buf, RegisterIdentificationVersion1); err != nil {
+		t.Error("ID serialize error!")
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	payload2 := PayloadRegisterIdentification{}
+	if err := payload2.Deserialize(r, RegisterIdentificationVersion1); err != nil {
+		t.Error("ID deserialize error!")
+	}
+
+	value1 := payload2.Contents[0].Values[0]
+	value2 := payload2.Contents[1].Values[0]
+	if value1.ExpiryType != ExpiryHeight || value1.Expiry != 1000 ||
+		value2.ExpiryType != ExpiryTimestamp || value2.Expiry != 1539155763 {
+		t.Error("ID content values expiry deserialize error!")
+	}
+
+	if value1.IsExpired(999, 0) || !value1.IsExpired(1000, 0) {
+		t.Error("ID content value height expiry error!")
+	}
+
+	if value2.IsExpired(0, 1539155762) || !value2.IsExpired(0, 1539155763) {
+		t.Error("ID content value timestamp expiry error!")
+	}
+
+	payload2.Contents[0].Values[0].ExpiryType = ExpiryNone
+	payload2.Contents[1].Values[0].ExpiryType = ExpiryNone
+	if payload2.Version() != RegisterIdentificationVersion {
+		t.Error("ID payload without expiry should keep version 0!")
+	}
+}
+
+func TestPayloadRegisterIdentificationBatch_Deserialize(t *testing.T) {
+	payload := &PayloadRegisterIdentificationBatch{
+		Entries: []PayloadRegisterIdentification{
+			PayloadRegisterIdentification{
+				ID:   "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+				Sign: []byte{1, 1, 1},
+				Contents: []RegisterIdentificationContent{
+					RegisterIdentificationContent{
+						Path: "kyc/person/identityCard",
+						Values: []RegisterIdentificationValue{RegisterIdentificationValue{
+							DataHash: common.Uint256{2, 2, 2},
+							Proof:    "testproof1",
+						}}},
+				},
+			},
+			PayloadRegisterIdentification{
+				ID:   "iWdYqmhWzcFH7Vd4mLcQoFqfjV2Mw7cHAq",
+				Sign: []byte{2, 2, 2},
+				Contents: []RegisterIdentificationContent{
+					RegisterIdentificationContent{
+						Path: "kyc/person/phone",
+						Values: []RegisterIdentificationValue{RegisterIdentificationValue{
+							DataHash: common.Uint256{3, 3, 3},
+							Proof:    "testproof2",
+						}}},
+				},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := payload.Serialize(buf, RegisterIdentificationVersion); err != nil {
+		t.Error("ID batch serialize error!")
+	}
+
+	payload2 := PayloadRegisterIdentificationBatch{}
+	if err := payload2.Deserialize(bytes.NewReader(buf.Bytes()), RegisterIdentificationVersion); err != nil {
+		t.Error("ID batch deserialize error!")
+	}
+
+	if len(payload2.Entries) != 2 {
+		t.Fatal("ID batch entries deserialize error!")
+	}
+
+	entry := payload2.Entry("iWdYqmhWzcFH7Vd4mLcQoFqfjV2Mw7cHAq")
+	if entry == nil || entry.Contents[0].Path != "kyc/person/phone" ||
+		!entry.Contents[0].Values[0].DataHash.IsEqual(common.Uint256{3, 3, 3}) {
+		t.Error("ID batch entry deserialize error!")
+	}
+
+	if payload2.Entry("ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6") == nil {
+		t.Error("ID batch entry lookup error!")
+	}
+}
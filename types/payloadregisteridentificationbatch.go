@@ -0,0 +1,81 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+const RegisterIdentificationBatch = 0x0f
+
+const (
+	// MaxBatchEntries is the maximum number of IDs registered by a batch.
+	MaxBatchEntries = 256
+
+	// MaxBatchEntryContents is the maximum number of paths registered for an
+	// ID in a batch.
+	MaxBatchEntryContents = 32
+
+	// MaxBatchEntryValues is the maximum number of values of a path
+	// registered in a batch.
+	MaxBatchEntryValues = 8
+)
+
+// PayloadRegisterIdentificationBatch registers many IDs in one transaction.
+// Every entry is signed by its own ID and the transaction has an output to
+// each of the IDs. The payload version is the version of all the entries.
+type PayloadRegisterIdentificationBatch struct {
+	Entries []PayloadRegisterIdentification
+}
+
+func (p *PayloadRegisterIdentificationBatch) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadRegisterIdentificationBatch) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarUint(w, uint64(len(p.Entries))); err != nil {
+		return errors.New("[RegisterIdentificationBatch], Entries size serialize failed.")
+	}
+
+	for _, entry := range p.Entries {
+		if err := entry.Serialize(w, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PayloadRegisterIdentificationBatch) Deserialize(r io.Reader, version byte) error {
+	size, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[RegisterIdentificationBatch], Entries size deserialize failed.")
+	}
+	if size > MaxBatchEntries {
+		return errors.New("[RegisterIdentificationBatch], too many entries.")
+	}
+
+	p.Entries = make([]PayloadRegisterIdentification, size)
+	for i := uint64(0); i < size; i++ {
+		if err := p.Entries[i].Deserialize(r, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Entry returns the entry registering the ID, or nil if the batch does not
+// register it.
+func (p *PayloadRegisterIdentificationBatch) Entry(ID string) *PayloadRegisterIdentification {
+	for i := range p.Entries {
+		if p.Entries[i].ID == ID {
+			return &p.Entries[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+const RegisterServiceEndpoint = 0x0a
+const RegisterServiceEndpointVersion = 0x00
+
+const (
+	// MaxServiceEndpoints is the maximum number of endpoints of an ID.
+	MaxServiceEndpoints = 16
+
+	// MaxServiceTypeSize is the maximum length of an endpoint type.
+	MaxServiceTypeSize = 64
+
+	// MaxServiceEndpointSize is the maximum length of an endpoint.
+	MaxServiceEndpointSize = 512
+)
+
+// Well known service endpoint types. Endpoints of the other types must be
+// URLs.
+const (
+	ServiceTypeMessagingHub = "MessagingHub"
+	ServiceTypeHiveStorage  = "HiveStorage"
+	ServiceTypeCarrier      = "CarrierAddress"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+type ServiceEndpoint struct {
+	Type     string
+	Endpoint string
+}
+
+// PayloadRegisterServiceEndpoint replaces the service endpoints registered for
+// an ID, an empty endpoint list removes all of them. Sequence is the sequence
+// number of the ID the payload applies to, so the signed payload can not be
+// replayed once the ID has changed.
+type PayloadRegisterServiceEndpoint struct {
+	ID        string
+	Sequence  uint32
+	Endpoints []ServiceEndpoint
+}
+
+func (p *PayloadRegisterServiceEndpoint) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadRegisterServiceEndpoint) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarString(w, p.ID); err != nil {
+		return errors.New("[RegisterServiceEndpoint], ID serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Sequence); err != nil {
+		return errors.New("[RegisterServiceEndpoint], Sequence serialize failed.")
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Endpoints))); err != nil {
+		return errors.New("[RegisterServiceEndpoint], Endpoints size serialize failed.")
+	}
+
+	for _, endpoint := range p.Endpoints {
+		if err := endpoint.Serialize(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PayloadRegisterServiceEndpoint) Deserialize(r io.Reader, version byte) error {
+	var err error
+	p.ID, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[RegisterServiceEndpoint], ID deserialize failed.")
+	}
+
+	p.Sequence, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[RegisterServiceEndpoint], Sequence deserialize failed.")
+	}
+
+	size, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return errors.New("[RegisterServiceEndpoint], Endpoints size deserialize failed.")
+	}
+	if size > MaxServiceEndpoints {
+		return errors.New("[RegisterServiceEndpoint], too many endpoints.")
+	}
+
+	p.Endpoints = make([]ServiceEndpoint, size)
+	for i := uint64(0); i < size; i++ {
+		if err := p.Endpoints[i].Deserialize(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PayloadRegisterServiceEndpoint) GetData() []byte {
+	return signedData(RegisterServiceEndpoint, p.Data(RegisterServiceEndpointVersion))
+}
+
+// signedData returns the data of a payload bound to the sequence number of an
+// ID, prefixed with the transaction type. Those payloads serialize to the
+// same bytes for the same ID and sequence number, so a signature of one can
+// not be replayed as another.
+func signedData(txType byte, data []byte) []byte {
+	return append([]byte{txType}, data...)
+}
+
+func (e *ServiceEndpoint) Serialize(w io.Writer) error {
+	if err := common.WriteVarString(w, e.Type); err != nil {
+		return errors.New("[ServiceEndpoint], Type serialize failed.")
+	}
+
+	if err := common.WriteVarString(w, e.Endpoint); err != nil {
+		return errors.New("[ServiceEndpoint], Endpoint serialize failed.")
+	}
+
+	return nil
+}
+
+func (e *ServiceEndpoint) Deserialize(r io.Reader) error {
+	var err error
+	e.Type, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[ServiceEndpoint], Type deserialize failed.")
+	}
+
+	e.Endpoint, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[ServiceEndpoint], Endpoint deserialize failed.")
+	}
+
+	return nil
+}
+
+// Validate checks the size and the format of the endpoint. Carrier addresses
+// are base58 strings, the endpoints of all other types must be absolute URLs.
+func (e *ServiceEndpoint) Validate() error {
+	if len(e.Type) == 0 || len(e.Type) > MaxServiceTypeSize {
+		return errors.New("[ServiceEndpoint], invalid type size.")
+	}
+	for _, c := range e.Type {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return errors.New("[ServiceEndpoint], type must be alphanumeric.")
+		}
+	}
+
+	if len(e.Endpoint) == 0 || len(e.Endpoint) > MaxServiceEndpointSize {
+		return errors.New("[ServiceEndpoint], invalid endpoint size.")
+	}
+
+	if e.Type == ServiceTypeCarrier {
+		for _, c := range e.Endpoint {
+			if !bytes.ContainsRune([]byte(base58Alphabet), c) {
+				return errors.New("[ServiceEndpoint], invalid carrier address.")
+			}
+		}
+		return nil
+	}
+
+	u, err := url.Parse(e.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("[ServiceEndpoint], endpoint is not a valid URL.")
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPayloadRegisterServiceEndpoint_Deserialize(t *testing.T) {
+	payload := &PayloadRegisterServiceEndpoint{
+		ID:       "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+		Sequence: 3,
+		Endpoints: []ServiceEndpoint{
+			{Type: ServiceTypeHiveStorage, Endpoint: "https://hive.example.org/vault"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := payload.Serialize(buf, RegisterServiceEndpointVersion); err != nil {
+		t.Fatal("service endpoint serialize error:", err)
+	}
+
+	payload2 := PayloadRegisterServiceEndpoint{}
+	if err := payload2.Deserialize(bytes.NewReader(buf.Bytes()), RegisterServiceEndpointVersion); err != nil {
+		t.Fatal("service endpoint deserialize error:", err)
+	}
+	if payload2.ID != payload.ID || payload2.Sequence != 3 || len(payload2.Endpoints) != 1 ||
+		payload2.Endpoints[0] != payload.Endpoints[0] {
+		t.Error("service endpoint deserialize error!")
+	}
+
+	// The sequence is signed, a payload signed for another sequence does
+	// not verify.
+	replayed := *payload
+	replayed.Sequence = 4
+	if bytes.Equal(replayed.GetData(), payload.GetData()) {
+		t.Error("service endpoint signed data does not cover the sequence!")
+	}
+}
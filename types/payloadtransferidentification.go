@@ -0,0 +1,69 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.Utility/common"
+)
+
+const TransferIdentification = 0x0b
+const TransferIdentificationVersion = 0x00
+
+// PayloadTransferIdentification hands the control of an ID over to another
+// ID. The transaction must be signed by both the current and the new
+// controller, the paths registered for the ID are kept. Sequence is the
+// sequence number of the ID the transfer applies to, so an old transfer can
+// not be replayed to take the control back.
+type PayloadTransferIdentification struct {
+	ID            string
+	Sequence      uint32
+	NewController string
+}
+
+func (p *PayloadTransferIdentification) Data(version byte) []byte {
+	buf := new(bytes.Buffer)
+	p.Serialize(buf, version)
+	return buf.Bytes()
+}
+
+func (p *PayloadTransferIdentification) Serialize(w io.Writer, version byte) error {
+	if err := common.WriteVarString(w, p.ID); err != nil {
+		return errors.New("[TransferIdentification], ID serialize failed.")
+	}
+
+	if err := common.WriteUint32(w, p.Sequence); err != nil {
+		return errors.New("[TransferIdentification], Sequence serialize failed.")
+	}
+
+	if err := common.WriteVarString(w, p.NewController); err != nil {
+		return errors.New("[TransferIdentification], NewController serialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadTransferIdentification) Deserialize(r io.Reader, version byte) error {
+	var err error
+	p.ID, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[TransferIdentification], ID deserialize failed.")
+	}
+
+	p.Sequence, err = common.ReadUint32(r)
+	if err != nil {
+		return errors.New("[TransferIdentification], Sequence deserialize failed.")
+	}
+
+	p.NewController, err = common.ReadVarString(r)
+	if err != nil {
+		return errors.New("[TransferIdentification], NewController deserialize failed.")
+	}
+
+	return nil
+}
+
+func (p *PayloadTransferIdentification) GetData() []byte {
+	return signedData(TransferIdentification, p.Data(TransferIdentificationVersion))
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPayloadTransferIdentification_Deserialize(t *testing.T) {
+	payload := &PayloadTransferIdentification{
+		ID:            "ij8rfb6A4Ri7c5CRE1nDVdVCUMuUxkk2c6",
+		Sequence:      1,
+		NewController: "igRn4VtAUB7hPkMrYMHt5f3xiQUMQJUFD2",
+	}
+
+	buf := new(bytes.Buffer)
+	if err := payload.Serialize(buf, TransferIdentificationVersion); err != nil {
+		t.Fatal("transfer serialize error:", err)
+	}
+
+	payload2 := PayloadTransferIdentification{}
+	if err := payload2.Deserialize(bytes.NewReader(buf.Bytes()), TransferIdentificationVersion); err != nil {
+		t.Fatal("transfer deserialize error:", err)
+	}
+	if payload2 != *payload {
+		t.Error("transfer deserialize error!")
+	}
+
+	// A transfer signed at another sequence, before the ID came back to
+	// the same controller, does not verify.
+	replayed := *payload
+	replayed.Sequence = 3
+	if bytes.Equal(replayed.GetData(), payload.GetData()) {
+		t.Error("transfer signed data does not cover the sequence!")
+	}
+}
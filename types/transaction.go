@@ -10,22 +10,83 @@ func IsRegisterIdentificationTx(tx *types.Transaction) bool {
 	return tx.TxType == RegisterIdentification
 }
 
+func IsRegisterServiceEndpointTx(tx *types.Transaction) bool {
+	return tx.TxType == RegisterServiceEndpoint
+}
+
+func IsTransferIdentificationTx(tx *types.Transaction) bool {
+	return tx.TxType == TransferIdentification
+}
+
+func IsSetRecoveryGuardiansTx(tx *types.Transaction) bool {
+	return tx.TxType == SetRecoveryGuardians
+}
+
+func IsRecoverIdentificationTx(tx *types.Transaction) bool {
+	return tx.TxType == RecoverIdentification
+}
+
+func IsCancelRecoveryTx(tx *types.Transaction) bool {
+	return tx.TxType == CancelRecovery
+}
+
+func IsRegisterIdentificationBatchTx(tx *types.Transaction) bool {
+	return tx.TxType == RegisterIdentificationBatch
+}
+
+func IsAnchorTx(tx *types.Transaction) bool {
+	return tx.TxType == Anchor
+}
+
+// IsIdentificationTx returns whether the transaction must be signed by the
+// controller of the identification it operates on, or by its guardians.
+func IsIdentificationTx(tx *types.Transaction) bool {
+	return IsRegisterIdentificationTx(tx) || IsRegisterServiceEndpointTx(tx) ||
+		IsTransferIdentificationTx(tx) || IsSetRecoveryGuardiansTx(tx) ||
+		IsRecoverIdentificationTx(tx) || IsCancelRecoveryTx(tx) ||
+		IsRegisterIdentificationBatchTx(tx)
+}
+
 func init() {
 
 	txTypeStr := types.TxTypeStr
 	types.TxTypeStr = func(txType types.TxType) string {
-		if txType == RegisterIdentification {
+		switch txType {
+		case RegisterIdentification:
 			return "RegisterIdentification"
+		case RegisterServiceEndpoint:
+			return "RegisterServiceEndpoint"
+		case TransferIdentification:
+			return "TransferIdentification"
+		case SetRecoveryGuardians:
+			return "SetRecoveryGuardians"
+		case RecoverIdentification:
+			return "RecoverIdentification"
+		case CancelRecovery:
+			return "CancelRecovery"
+		case RegisterIdentificationBatch:
+			return "RegisterIdentificationBatch"
+		case Anchor:
+			return "Anchor"
 		}
 		return txTypeStr(txType)
 	}
 
 	getDataContainer := types.GetDataContainer
 	types.GetDataContainer = func(programHash *common.Uint168, tx *types.Transaction) interfaces.IDataContainer {
-		if tx.TxType == RegisterIdentification {
+		// Every entry of a batch is signed by its own ID.
+		if IsRegisterIdentificationBatchTx(tx) {
+			entries := tx.Payload.(*PayloadRegisterIdentificationBatch).Entries
+			for i := range entries {
+				entryHash, err := common.Uint168FromAddress(entries[i].ID)
+				if err == nil && programHash.IsEqual(*entryHash) {
+					return &entries[i]
+				}
+			}
+		} else if IsIdentificationTx(tx) {
 			for _, output := range tx.Outputs {
 				if programHash[0] == common.PrefixRegisterId && programHash.IsEqual(output.ProgramHash) {
-					return tx.Payload.(*PayloadRegisterIdentification)
+					return tx.Payload.(interfaces.IDataContainer)
 				}
 			}
 		}
@@ -34,8 +95,23 @@ func init() {
 
 	getPayloadByTxType := types.GetPayloadByTxType
 	types.GetPayloadByTxType = func(txType types.TxType) (types.Payload, error) {
-		if txType == RegisterIdentification {
+		switch txType {
+		case RegisterIdentification:
 			return &PayloadRegisterIdentification{}, nil
+		case RegisterServiceEndpoint:
+			return &PayloadRegisterServiceEndpoint{}, nil
+		case TransferIdentification:
+			return &PayloadTransferIdentification{}, nil
+		case SetRecoveryGuardians:
+			return &PayloadSetRecoveryGuardians{}, nil
+		case RecoverIdentification:
+			return &PayloadRecoverIdentification{}, nil
+		case CancelRecovery:
+			return &PayloadCancelRecovery{}, nil
+		case RegisterIdentificationBatch:
+			return &PayloadRegisterIdentificationBatch{}, nil
+		case Anchor:
+			return &PayloadAnchor{}, nil
 		}
 		return getPayloadByTxType(txType)
 	}